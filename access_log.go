@@ -0,0 +1,142 @@
+package mux
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	gorillamux "github.com/gorilla/mux"
+)
+
+// AccessLogFormat defines format of access log lines.
+type AccessLogFormat int
+
+const (
+	// AccessLogJSON writes entries as JSON objects.
+	AccessLogJSON AccessLogFormat = iota
+	// AccessLogCommon writes entries in Common Log Format.
+	AccessLogCommon
+	// AccessLogCombined writes entries in Combined Log Format.
+	AccessLogCombined
+)
+
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AccessLogEntry describes served request. Path is route template rather
+// than requested path, so logs keep low cardinality.
+type AccessLogEntry struct {
+	Time      time.Time     `json:"time"`
+	Method    string        `json:"method"`
+	Path      string        `json:"path,omitempty"`
+	Route     string        `json:"route,omitempty"`
+	Proto     string        `json:"proto"`
+	Status    int           `json:"status"`
+	Size      int64         `json:"bytes"`
+	Duration  time.Duration `json:"duration_ns"`
+	RemoteIP  string        `json:"remote_ip"`
+	Referer   string        `json:"referer,omitempty"`
+	UserAgent string        `json:"user_agent,omitempty"`
+	RequestID string        `json:"request_id,omitempty"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// AccessLog returns `AroundFunc` writing entry of every request to out.
+// Error returned by handler is handled by `HandleError` before logging to
+// observe final status. It is still returned to enclosing AroundFuncs, which
+// don't handle it again. Requests not matched by any route are logged with
+// empty path template when AccessLog is used by root router.
+func AccessLog(out io.Writer, format AccessLogFormat) AroundFunc {
+	var mu sync.Mutex
+	return func(w http.ResponseWriter, r *http.Request, next func() error) error {
+		start := time.Now()
+		err := next()
+		if err != nil {
+			HandleError(err, w, r)
+		}
+		entry := newAccessLogEntry(w, r, start, err)
+
+		mu.Lock()
+		entry.write(out, format)
+		mu.Unlock()
+
+		if err != nil {
+			return &handledError{err}
+		}
+		return nil
+	}
+}
+
+func newAccessLogEntry(w http.ResponseWriter, r *http.Request, start time.Time, err error) *AccessLogEntry {
+	entry := &AccessLogEntry{
+		Time:      start,
+		Method:    r.Method,
+		Proto:     r.Proto,
+		Status:    http.StatusOK,
+		Duration:  time.Since(start),
+		RemoteIP:  r.RemoteAddr,
+		Referer:   r.Referer(),
+		UserAgent: r.UserAgent(),
+		RequestID: RequestID(r.Context()),
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		entry.RemoteIP = host
+	}
+	if route := gorillamux.CurrentRoute(r); route != nil {
+		entry.Route = route.GetName()
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			entry.Path = tpl
+		}
+	}
+	if rw, ok := w.(ResponseWriter); ok {
+		if rw.Status() != 0 {
+			entry.Status = rw.Status()
+		}
+		entry.Size = rw.Size()
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+func (e *AccessLogEntry) write(out io.Writer, format AccessLogFormat) {
+	switch format {
+	case AccessLogCommon, AccessLogCombined:
+		line := fmt.Sprintf("%s - - [%s] %s %d %s",
+			clfValue(e.RemoteIP),
+			e.Time.Format(clfTimeFormat),
+			strconv.Quote(e.Method+" "+clfValue(e.Path)+" "+e.Proto),
+			e.Status,
+			clfSize(e.Size),
+		)
+		if format == AccessLogCombined {
+			line += fmt.Sprintf(" %s %s", strconv.Quote(clfValue(e.Referer)), strconv.Quote(clfValue(e.UserAgent)))
+		}
+		fmt.Fprintln(out, line)
+	default:
+		data, err := json.Marshal(e)
+		if err != nil {
+			return
+		}
+		out.Write(append(data, '\n'))
+	}
+}
+
+func clfValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func clfSize(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return strconv.FormatInt(n, 10)
+}
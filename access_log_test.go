@@ -0,0 +1,171 @@
+package mux_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func newAccessLogRouter(buf *bytes.Buffer, format mux.AccessLogFormat) *mux.Router {
+	router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+	router.UseAround(mux.AccessLog(buf, format))
+	router.HandleFunc("/users/{id}", okHandler).Name("user")
+	router.HandleFunc("/orders/{id}", notFoundHandler)
+	return router
+}
+
+func TestAccessLogJSON(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Path     string
+		Expected mux.AccessLogEntry
+	}{
+		{
+			Name: "OK",
+			Path: "/users/42",
+			Expected: mux.AccessLogEntry{
+				Method: "GET", Path: "/users/{id}", Route: "user", Proto: "HTTP/1.1",
+				Status: http.StatusOK, Size: 2, RemoteIP: "192.0.2.1",
+			},
+		},
+		{
+			Name: "Error",
+			Path: "/orders/42",
+			Expected: mux.AccessLogEntry{
+				Method: "GET", Path: "/orders/{id}", Proto: "HTTP/1.1",
+				Status: http.StatusNotFound, Size: 39, RemoteIP: "192.0.2.1", Error: "User not found",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			router := newAccessLogRouter(&buf, mux.AccessLogJSON)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tc.Path, nil))
+
+			if w.Code != tc.Expected.Status {
+				t.Fatal(newStatusError(w.Code, tc.Expected.Status))
+			}
+
+			var entry mux.AccessLogEntry
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry.Time.IsZero() || entry.Duration <= 0 {
+				t.Fatalf("failed: missing time or duration in %s", buf.String())
+			}
+			entry.Time, entry.Duration = tc.Expected.Time, tc.Expected.Duration
+			if entry != tc.Expected {
+				t.Fatalf("failed: got %+v, expected %+v", entry, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestAccessLogCLF(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Format   mux.AccessLogFormat
+		Expected string
+	}{
+		{
+			Name:     "Common",
+			Format:   mux.AccessLogCommon,
+			Expected: `^192\.0\.2\.1 - - \[[^\]]+\] "GET /users/\{id\} HTTP/1\.1" 200 2\n$`,
+		},
+		{
+			Name:     "Combined",
+			Format:   mux.AccessLogCombined,
+			Expected: `^192\.0\.2\.1 - - \[[^\]]+\] "GET /users/\{id\} HTTP/1\.1" 200 2 "https://example\.com/" "test-agent"\n$`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			router := newAccessLogRouter(&buf, tc.Format)
+
+			r := httptest.NewRequest("GET", "/users/42", nil)
+			r.Header.Set("Referer", "https://example.com/")
+			r.Header.Set("User-Agent", "test-agent")
+			router.ServeHTTP(httptest.NewRecorder(), r)
+
+			if !regexp.MustCompile(tc.Expected).MatchString(buf.String()) {
+				t.Fatalf("failed: got %q, expected to match %s", buf.String(), tc.Expected)
+			}
+		})
+	}
+}
+
+func TestAccessLogEnclosingAround(t *testing.T) {
+	var buf bytes.Buffer
+	var seen error
+	router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+	router.UseAround(func(w http.ResponseWriter, r *http.Request, next func() error) error {
+		seen = next()
+		return seen
+	})
+	router.UseAround(mux.AccessLog(&buf, mux.AccessLogJSON))
+	router.HandleFunc("/orders/{id}", notFoundHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/orders/1", nil))
+
+	if w.Code != http.StatusNotFound {
+		t.Fatal(newStatusError(w.Code, http.StatusNotFound))
+	}
+	var e *mux.HTTPError
+	if !errors.As(seen, &e) || e.Code != http.StatusNotFound {
+		t.Fatalf("failed: enclosing around got %v, expected handler error", seen)
+	}
+	if n := bytes.Count(w.Body.Bytes(), []byte(`"code":404`)); n != 1 {
+		t.Fatalf("failed: error rendered %d times: %s", n, w.Body.String())
+	}
+	if bytes.Count(buf.Bytes(), []byte("\n")) != 1 {
+		t.Fatalf("failed: got log %q, expected one entry", buf.String())
+	}
+}
+
+func TestAccessLogUnmatched(t *testing.T) {
+	testCases := []struct {
+		Name   string
+		Method string
+		Path   string
+		Code   int
+	}{
+		{Name: "NotFound", Method: "GET", Path: "/missing", Code: http.StatusNotFound},
+		{Name: "MethodNotAllowed", Method: "DELETE", Path: "/users/1", Code: http.StatusMethodNotAllowed},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			router := mux.NewRouter()
+			router.UseAround(mux.AccessLog(&buf, mux.AccessLogJSON))
+			router.HandleFunc("/users/{id}", okHandler).Methods("GET")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tc.Method, tc.Path, nil))
+
+			if w.Code != tc.Code {
+				t.Fatal(newStatusError(w.Code, tc.Code))
+			}
+			var entry mux.AccessLogEntry
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry.Status != tc.Code || entry.Path != "" || entry.Method != tc.Method {
+				t.Fatalf("failed: got %+v", entry)
+			}
+		})
+	}
+}
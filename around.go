@@ -0,0 +1,106 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	gorillamux "github.com/gorilla/mux"
+)
+
+// AroundFunc wraps the rest of handler chain. Calling next runs following
+// middlewares and handler and returns their error before it is handled, so
+// AroundFunc can inspect, replace or suppress it. Status and size of
+// response are available through `ResponseWriter`. Returned error is handled
+// by `Wrapper` or passed to enclosing AroundFunc.
+//
+// Errors are captured from handlers and middlewares wrapped by default
+// wrapper, see `NewDefaultWrapper`.
+type AroundFunc func(w http.ResponseWriter, r *http.Request, next func() error) error
+
+// errorSlot receives error of handler chain called by `AroundFunc`.
+type errorSlot struct {
+	err error
+}
+
+func errorSlotFrom(r *http.Request) *errorSlot {
+	slot, _ := r.Context().Value(errorSlotContextKey).(*errorSlot)
+	return slot
+}
+
+// UseAround appends an AroundFunc to the chain. AroundFuncs of root router
+// also wrap responses to requests not matched by any route.
+func (r *Router) UseAround(fns ...AroundFunc) {
+	middlewares := []gorillamux.MiddlewareFunc{}
+	for _, fn := range fns {
+		middlewares = append(middlewares, aroundMiddleware(r.Wrapper, fn))
+	}
+	r.mux.Use(middlewares...)
+	if !r.subrouter {
+		r.arounds = append(r.arounds, middlewares...)
+		r.withCustomHandlers()
+	}
+}
+
+// handledError is error already handled by `AroundFunc`. It is passed to
+// enclosing AroundFunc, but not handled again.
+type handledError struct {
+	err error
+}
+
+func (e *handledError) Error() string   { return e.err.Error() }
+func (e *handledError) Unwrap() []error { return []error{e.err, ErrHandled} }
+
+// isSuccess reports whether err is nil or plain `ErrHandled`.
+func isSuccess(err error) bool {
+	var he *handledError
+	return err == nil || errors.Is(err, ErrHandled) && !errors.As(err, &he)
+}
+
+// UseAround appends an AroundFunc to the route's chain. See Route.Use().
+func (r *Route) UseAround(fns ...AroundFunc) *Route {
+	mws := make([]routeMiddleware, 0, len(fns))
+	for _, fn := range fns {
+		mws = append(mws, routeMiddleware{around: fn})
+	}
+	return r.use(mws)
+}
+
+// HandleError handles err by wrapper of current request. It lets
+// `AroundFunc` write error response itself and return nil.
+func HandleError(err error, w http.ResponseWriter, r *http.Request) {
+	wr := scopedWrapper(r)
+	if wr == nil {
+		wr, _ = r.Context().Value(aroundWrapperContextKey).(Wrapper)
+	}
+	if wr == nil {
+		wr = NewDefaultWrapper(basicErrorFunc)
+	}
+	wr.HandleError(err, w, r)
+}
+
+func aroundMiddleware(wr Wrapper, fn AroundFunc) gorillamux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrapResponseWriter(w)
+			w = rw.writer()
+			outer := errorSlotFrom(r)
+			if _, ok := r.Context().Value(aroundWrapperContextKey).(Wrapper); !ok {
+				r = r.WithContext(context.WithValue(r.Context(), aroundWrapperContextKey, wr))
+			}
+			err := fn(w, r, func() error {
+				slot := &errorSlot{}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), errorSlotContextKey, slot)))
+				return slot.err
+			})
+			if isSuccess(err) {
+				return
+			}
+			if outer != nil {
+				outer.err = err
+				return
+			}
+			wr.HandleError(err, rw, r)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package mux_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func TestAroundFunc(t *testing.T) {
+	created := func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+		return nil
+	}
+	notFound := func(w http.ResponseWriter, r *http.Request) error {
+		return mux.ErrNotFound
+	}
+	deny := func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		return nil, mux.ErrForbidden
+	}
+
+	testCases := []struct {
+		Name        string
+		Handler     mux.HandlerFunc
+		Middlewares []mux.MiddlewareFunc
+		Around      mux.AroundFunc
+		Code        int
+		Expected    string
+	}{
+		{
+			Name:    "Status",
+			Handler: created,
+			Around: func(w http.ResponseWriter, r *http.Request, next func() error) error {
+				err := next()
+				rw := w.(mux.ResponseWriter)
+				w.Header().Set("X-Observed", fmt.Sprintf("%d %d", rw.Status(), rw.Size()))
+				return err
+			},
+			Code:     http.StatusCreated,
+			Expected: "201 5",
+		},
+		{
+			Name:    "Replace",
+			Handler: notFound,
+			Around: func(w http.ResponseWriter, r *http.Request, next func() error) error {
+				if err := next(); errors.Is(err, mux.ErrNotFound) {
+					w.Header().Set("X-Observed", "not found")
+					return mux.ErrGone
+				}
+				return nil
+			},
+			Code:     http.StatusGone,
+			Expected: "not found",
+		},
+		{
+			Name:    "Suppress",
+			Handler: notFound,
+			Around: func(w http.ResponseWriter, r *http.Request, next func() error) error {
+				if err := next(); err != nil {
+					w.WriteHeader(http.StatusAccepted)
+				}
+				return nil
+			},
+			Code: http.StatusAccepted,
+		},
+		{
+			Name:        "Middleware",
+			Handler:     created,
+			Middlewares: []mux.MiddlewareFunc{deny},
+			Around: func(w http.ResponseWriter, r *http.Request, next func() error) error {
+				err := next()
+				if errors.Is(err, mux.ErrForbidden) {
+					w.Header().Set("X-Observed", "forbidden")
+				}
+				return err
+			},
+			Code:     http.StatusForbidden,
+			Expected: "forbidden",
+		},
+		{
+			Name:    "HandleError",
+			Handler: notFound,
+			Around: func(w http.ResponseWriter, r *http.Request, next func() error) error {
+				if err := next(); err != nil {
+					mux.HandleError(err, w, r)
+				}
+				w.Header().Set("X-Observed", http.StatusText(w.(mux.ResponseWriter).Status()))
+				return nil
+			},
+			Code:     http.StatusNotFound,
+			Expected: "Not Found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+			router.UseAround(tc.Around)
+			router.Use(tc.Middlewares...)
+			router.HandleFunc("/", tc.Handler)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+			if w.Code != tc.Code {
+				t.Fatal(newStatusError(w.Code, tc.Code))
+			}
+			if got := w.Header().Get("X-Observed"); got != tc.Expected {
+				t.Fatalf("failed: got %q, expected %q", got, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestAroundFuncOrder(t *testing.T) {
+	var calls []string
+	around := func(name string) mux.AroundFunc {
+		return func(w http.ResponseWriter, r *http.Request, next func() error) error {
+			calls = append(calls, name)
+			err := next()
+			calls = append(calls, name+":"+err.Error())
+			return errors.New(name)
+		}
+	}
+
+	router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+	router.UseAround(around("router"))
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		calls = append(calls, "handler")
+		return errors.New("handler")
+	}).UseAround(around("route"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatal(newStatusError(w.Code, http.StatusInternalServerError))
+	}
+	expected := "router route handler route:handler router:route"
+	if got := strings.Join(calls, " "); got != expected {
+		t.Fatalf("failed: got %q, expected %q", got, expected)
+	}
+}
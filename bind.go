@@ -0,0 +1,172 @@
+package mux
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Bind fills struct pointed by dst from request. Fields are bound by tags:
+// `path` from route variables, `query` from URL query, `header` from
+// request headers and `json` fields from JSON body. Body is decoded first,
+// so tagged parameters take precedence, and only `json` fields are set from
+// it. Conversion failures are returned as one `HTTPError` with status 400
+// and field violations as `Details`. Fields of types which can't be
+// converted from string are reported as plain error. Bound struct is then
+// checked by `Validate`.
+func Bind(r *http.Request, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("mux: Bind destination must be a non-nil pointer to struct")
+	}
+
+	t := rv.Elem().Type()
+	if err := checkBindType(t); err != nil {
+		return err
+	}
+
+	var violations FieldErrors
+	if hasBodyFields(t) {
+		body := reflect.New(t)
+		body.Elem().Set(rv.Elem())
+		err := decodeJSON(nil, r, body.Interface(), &jsonConfig{})
+		copyBodyFields(rv.Elem(), body.Elem())
+		if err != nil {
+			var e *HTTPError
+			if !errors.As(err, &e) || e.Code != http.StatusBadRequest {
+				return err
+			}
+			if len(e.Details) == 0 {
+				violations.Add("body", "invalid", e.Message)
+			}
+			violations = append(violations, e.Details...)
+		}
+	}
+
+	bindFields(r, rv.Elem(), &violations)
+	if err := violations.Err(http.StatusBadRequest, "Invalid request"); err != nil {
+		return err
+	}
+	return Validate(dst)
+}
+
+var bindSources = []string{"path", "query", "header"}
+
+func bindFields(r *http.Request, v reflect.Value, violations *FieldErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fv := v.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag == "" {
+			bindFields(r, fv, violations)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		for _, source := range bindSources {
+			name, ok := field.Tag.Lookup(source)
+			if !ok || name == "-" {
+				continue
+			}
+			values := lookupValues(r, source, name)
+			if len(values) == 0 {
+				continue
+			}
+			if err := setValues(fv, values); err != nil {
+				violations.Add(name, "type", err.Error())
+			}
+		}
+	}
+}
+
+func lookupValues(r *http.Request, source, name string) []string {
+	switch source {
+	case "path":
+		if value, ok := Vars(r)[name]; ok {
+			return []string{value}
+		}
+	case "query":
+		return r.URL.Query()[name]
+	case "header":
+		return r.Header.Values(name)
+	}
+	return nil
+}
+
+// bindTypes caches results of `checkBindType` by struct type.
+var bindTypes sync.Map
+
+// checkBindType reports fields bound from path, query or headers which
+// can't be converted from string. Result is cached, so misuse is reported
+// on every request rather than only when value is sent.
+func checkBindType(t reflect.Type) error {
+	if err, ok := bindTypes.Load(t); ok {
+		err, _ := err.(error)
+		return err
+	}
+	err := checkBindFields(t)
+	bindTypes.Store(t, err)
+	return err
+}
+
+func checkBindFields(t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.Tag == "" {
+			if err := checkBindFields(field.Type); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		for _, source := range bindSources {
+			if name, ok := field.Tag.Lookup(source); ok && name != "-" {
+				if err := checkType(field.Type); err != nil {
+					return fmt.Errorf("mux: Bind field %s.%s: %w", t.Name(), field.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// copyBodyFields copies fields tagged with `json` from src to dst, so body
+// can't set fields bound from other sources.
+func copyBodyFields(dst, src reflect.Value) {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isBodyField(field) {
+			dst.Field(i).Set(src.Field(i))
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			copyBodyFields(dst.Field(i), src.Field(i))
+		}
+	}
+}
+
+func isBodyField(field reflect.StructField) bool {
+	name, ok := field.Tag.Lookup("json")
+	return ok && field.PkgPath == "" && !strings.HasPrefix(name, "-")
+}
+
+// hasBodyFields reports whether struct has fields tagged with `json`.
+func hasBodyFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if isBodyField(field) {
+			return true
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && hasBodyFields(field.Type) {
+			return true
+		}
+	}
+	return false
+}
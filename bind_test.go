@@ -0,0 +1,149 @@
+package mux_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikarik/mux"
+)
+
+type listOrdersRequest struct {
+	UserID  int       `path:"id" json:"-"`
+	Page    int       `query:"page" json:"-"`
+	Active  *bool     `query:"active" json:"-"`
+	Tags    []string  `query:"tag" json:"-"`
+	IDs     []int64   `query:"ids" json:"-"`
+	Since   time.Time `query:"since" json:"-"`
+	Tenant  string    `header:"X-Tenant" json:"-"`
+	Comment string    `json:"comment"`
+}
+
+func TestBind(t *testing.T) {
+	active := true
+	since := time.Date(2019, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		Name     string
+		URL      string
+		Body     string
+		Code     int
+		Expected listOrdersRequest
+		Details  []mux.FieldError
+	}{
+		{
+			Name: "OK",
+			URL:  "/users/42/orders?page=2&active=true&tag=a&tag=b&ids=1,2,3&since=2019-09-01",
+			Body: `{"comment":"hello"}`,
+			Code: http.StatusOK,
+			Expected: listOrdersRequest{
+				UserID:  42,
+				Page:    2,
+				Active:  &active,
+				Tags:    []string{"a", "b"},
+				IDs:     []int64{1, 2, 3},
+				Since:   since,
+				Tenant:  "acme",
+				Comment: "hello",
+			},
+		},
+		{
+			Name: "Invalid",
+			URL:  "/users/42/orders?page=two&active=maybe&ids=1,x&since=yesterday",
+			Body: `{"comment":1}`,
+			Code: http.StatusBadRequest,
+			Details: []mux.FieldError{
+				{Path: "comment", Code: "type", Message: "must be string"},
+				{Path: "page", Code: "type", Message: "must be an integer"},
+				{Path: "active", Code: "type", Message: "must be a boolean"},
+				{Path: "ids", Code: "type", Message: "must be an integer"},
+				{Path: "since", Code: "type", Message: "must be a time in RFC 3339 format"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+			router.HandleFunc("/users/{id}/orders", func(w http.ResponseWriter, r *http.Request) error {
+				var req listOrdersRequest
+				if err := mux.Bind(r, &req); err != nil {
+					return err
+				}
+				if !reflect.DeepEqual(req, tc.Expected) {
+					t.Fatalf("got %+v, expected %+v", req, tc.Expected)
+				}
+				return nil
+			})
+
+			r := httptest.NewRequest("GET", tc.URL, strings.NewReader(tc.Body))
+			r.Header.Set("X-Tenant", "acme")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+			if tc.Code == http.StatusOK {
+				return
+			}
+
+			var e mux.HTTPError
+			if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(e.Details, tc.Details) {
+				t.Fatalf("got details %+v, expected %+v", e.Details, tc.Details)
+			}
+		})
+	}
+}
+
+func TestBindDestination(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	var dst int
+	if err := mux.Bind(r, &dst); err == nil {
+		t.Fatal("expected error for non-struct destination")
+	}
+}
+
+func TestBindUnsupportedType(t *testing.T) {
+	type request struct {
+		M map[string]string `query:"m"`
+	}
+
+	for _, url := range []string{"/?m=x", "/"} {
+		var dst request
+		err := mux.Bind(httptest.NewRequest("GET", url, nil), &dst)
+		var e *mux.HTTPError
+		if err == nil || errors.As(err, &e) {
+			t.Fatalf("%s: got %v, expected unsupported type error", url, err)
+		}
+	}
+}
+
+func TestBindBodyOnlyJSONFields(t *testing.T) {
+	type request struct {
+		Tenant  string `header:"X-Tenant"`
+		Page    int    `query:"page"`
+		Comment string `json:"comment"`
+	}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"Tenant":"x","Page":5,"comment":"hi"}`))
+	dst := request{Page: 1}
+	if err := mux.Bind(r, &dst); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := request{Page: 1, Comment: "hi"}
+	if dst != expected {
+		t.Fatalf("got %+v, expected %+v", dst, expected)
+	}
+}
@@ -0,0 +1,158 @@
+package mux
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textUnmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// timeLayouts are tried in order when converting to `time.Time`.
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
+// setValues converts values into v. Slices are filled by all values or by
+// comma separated items of a single value. Other kinds use the first value.
+func setValues(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, s := range values {
+			if err := setValue(slice.Index(i), strings.TrimSpace(s)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return setValue(v, values[0])
+}
+
+// setValue converts s into v.
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		for _, layout := range timeLayouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return errors.New("must be a time in RFC 3339 format")
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return errors.New("must be a duration")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshalType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return fmt.Errorf("must be a valid %s", v.Type().Name())
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return errors.New("must be a boolean")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a non-negative integer")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return errors.New("must be a number")
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return setValues(v, []string{s})
+		}
+		v.SetBytes([]byte(s))
+	default:
+		return &unsupportedTypeError{v.Type()}
+	}
+	return nil
+}
+
+// unsupportedTypeError reports type which can't be converted from string.
+// It is a programming error rather than malformed input.
+type unsupportedTypeError struct {
+	typ reflect.Type
+}
+
+func (e *unsupportedTypeError) Error() string {
+	return fmt.Sprintf("mux: unsupported type %s", e.typ)
+}
+
+// checkType reports whether values of t can be set by `setValues`.
+func checkType(t reflect.Type) error {
+	if t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 {
+		if err := checkScalarType(t.Elem()); err != nil {
+			return &unsupportedTypeError{t}
+		}
+		return nil
+	}
+	return checkScalarType(t)
+}
+
+func checkScalarType(t reflect.Type) error {
+	if t.Kind() == reflect.Ptr {
+		return checkScalarType(t.Elem())
+	}
+	if t == timeType || t == durationType || reflect.PtrTo(t).Implements(textUnmarshalType) {
+		return nil
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return nil
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+	}
+	return &unsupportedTypeError{t}
+}
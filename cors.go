@@ -0,0 +1,165 @@
+package mux
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	gorillamux "github.com/gorilla/mux"
+)
+
+// CORSOptions configures cross-origin resource sharing, see Router.UseCORS().
+type CORSOptions struct {
+	// AllowedOrigins lists allowed origins. Origin may contain "*" wildcard,
+	// e.g. "https://*.example.com". Empty list or "*" allows any origin
+	// with `Access-Control-Allow-Origin: *`, without credentials.
+	AllowedOrigins []string
+	// AllowedHeaders lists request headers allowed in preflight. Empty list
+	// allows headers requested by client.
+	AllowedHeaders []string
+	// ExposedHeaders lists response headers exposed to client.
+	ExposedHeaders []string
+	// AllowCredentials allows requests with credentials from origins listed
+	// in AllowedOrigins. It is ignored when any origin is allowed.
+	AllowCredentials bool
+	// MaxAge tells how long preflight result can be cached.
+	MaxAge time.Duration
+}
+
+// UseCORS adds CORS headers to responses of allowed origins and answers
+// preflight requests. Allowed methods are taken from methods of routes
+// matching preflight request. It is applied by router's ServeHTTP, so it
+// should be used on root router.
+func (r *Router) UseCORS(opts CORSOptions) {
+	r.cors = &opts
+}
+
+// serveCORS sets CORS headers and reports whether request is preflight
+// answered by the router.
+func (r *Router) serveCORS(w http.ResponseWriter, req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	h := w.Header()
+	h.Add("Vary", "Origin")
+
+	method := req.Header.Get("Access-Control-Request-Method")
+	if req.Method != http.MethodOptions || method == "" {
+		if r.cors.allowOrigin(origin) {
+			r.cors.setOrigin(h, origin)
+			if len(r.cors.ExposedHeaders) > 0 {
+				h.Set("Access-Control-Expose-Headers", strings.Join(r.cors.ExposedHeaders, ", "))
+			}
+		}
+		return false
+	}
+
+	methods := r.allowedMethods(req, method)
+	if len(methods) == 0 {
+		return false
+	}
+	h.Add("Vary", "Access-Control-Request-Method")
+	h.Add("Vary", "Access-Control-Request-Headers")
+	if r.cors.allowOrigin(origin) {
+		r.cors.setOrigin(h, origin)
+		h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+		if len(r.cors.AllowedHeaders) > 0 {
+			h.Set("Access-Control-Allow-Headers", strings.Join(r.cors.AllowedHeaders, ", "))
+		} else if headers := req.Header.Get("Access-Control-Request-Headers"); headers != "" {
+			h.Set("Access-Control-Allow-Headers", headers)
+		}
+		if r.cors.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(r.cors.MaxAge/time.Second)))
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
+
+// allowedMethods returns methods for which request matches some route.
+// Routes without method matcher allow requested method.
+func (r *Router) allowedMethods(req *http.Request, requested string) []string {
+	candidates := map[string]bool{requested: true}
+	r.mux.Walk(func(route *gorillamux.Route, _ *gorillamux.Router, _ []*gorillamux.Route) error {
+		methods, err := route.GetMethods()
+		if err == nil {
+			for _, m := range methods {
+				candidates[m] = true
+			}
+		}
+		return nil
+	})
+
+	var allowed []string
+	for method := range candidates {
+		probe := req.Clone(req.Context())
+		probe.Method = method
+		var match gorillamux.RouteMatch
+		if r.mux.Match(probe, &match) && match.MatchErr == nil {
+			allowed = append(allowed, method)
+		}
+	}
+	sort.Strings(allowed)
+	return allowed
+}
+
+// allowAnyOrigin reports whether origins are not restricted.
+func (o *CORSOptions) allowAnyOrigin() bool {
+	if len(o.AllowedOrigins) == 0 {
+		return true
+	}
+	for _, pattern := range o.AllowedOrigins {
+		if pattern == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *CORSOptions) allowOrigin(origin string) bool {
+	if o.allowAnyOrigin() {
+		return true
+	}
+	for _, pattern := range o.AllowedOrigins {
+		if matchWildcard(strings.ToLower(pattern), strings.ToLower(origin)) {
+			return true
+		}
+	}
+	return false
+}
+
+// setOrigin echoes listed origin, credentials are never allowed for any origin.
+func (o *CORSOptions) setOrigin(h http.Header, origin string) {
+	if o.allowAnyOrigin() {
+		h.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	h.Set("Access-Control-Allow-Origin", origin)
+	if o.AllowCredentials {
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// matchWildcard reports whether s matches pattern where "*" matches any
+// sequence of characters.
+func matchWildcard(pattern, s string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == s
+	}
+	if !strings.HasPrefix(s, parts[0]) {
+		return false
+	}
+	s = s[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(s, part)
+		if i < 0 {
+			return false
+		}
+		s = s[i+len(part):]
+	}
+	return strings.HasSuffix(s, parts[len(parts)-1])
+}
@@ -0,0 +1,197 @@
+package mux_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danikarik/mux"
+)
+
+func TestCORS(t *testing.T) {
+	router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+	router.UseCORS(mux.CORSOptions{
+		AllowedOrigins:   []string{"https://example.com", "https://*.example.org"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+	router.HandleFunc("/users", okHandler).Methods("GET", "POST")
+	router.HandleFunc("/users/{id}", okHandler).Methods("GET")
+	router.HandleFunc("/users/{id}", okHandler).Methods("DELETE")
+	router.HandleFunc("/any", okHandler)
+
+	testCases := []struct {
+		Name     string
+		Method   string
+		Path     string
+		Origin   string
+		Request  string
+		Headers  string
+		Code     int
+		Expected map[string]string
+	}{
+		{
+			Name:    "Preflight",
+			Method:  "OPTIONS",
+			Path:    "/users",
+			Origin:  "https://example.com",
+			Request: "POST",
+			Headers: "Content-Type",
+			Code:    http.StatusNoContent,
+			Expected: map[string]string{
+				"Access-Control-Allow-Origin":      "https://example.com",
+				"Access-Control-Allow-Methods":     "GET, POST",
+				"Access-Control-Allow-Headers":     "Content-Type",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Max-Age":           "600",
+			},
+		},
+		{
+			Name:    "PreflightSeparateRoutes",
+			Method:  "OPTIONS",
+			Path:    "/users/1",
+			Origin:  "https://api.example.org",
+			Request: "DELETE",
+			Code:    http.StatusNoContent,
+			Expected: map[string]string{
+				"Access-Control-Allow-Origin":  "https://api.example.org",
+				"Access-Control-Allow-Methods": "DELETE, GET",
+			},
+		},
+		{
+			Name:    "PreflightAnyMethod",
+			Method:  "OPTIONS",
+			Path:    "/any",
+			Origin:  "https://example.com",
+			Request: "PUT",
+			Code:    http.StatusNoContent,
+			Expected: map[string]string{
+				"Access-Control-Allow-Methods": "DELETE, GET, POST, PUT",
+			},
+		},
+		{
+			Name:    "PreflightDeniedOrigin",
+			Method:  "OPTIONS",
+			Path:    "/users",
+			Origin:  "https://evil.com",
+			Request: "POST",
+			Code:    http.StatusNoContent,
+			Expected: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			Name:    "PreflightNotFound",
+			Method:  "OPTIONS",
+			Path:    "/orders",
+			Origin:  "https://example.com",
+			Request: "GET",
+			Code:    http.StatusNotFound,
+		},
+		{
+			Name:   "Request",
+			Method: "GET",
+			Path:   "/users",
+			Origin: "https://example.com",
+			Code:   http.StatusOK,
+			Expected: map[string]string{
+				"Access-Control-Allow-Origin":   "https://example.com",
+				"Access-Control-Expose-Headers": "X-Request-ID",
+				"Vary":                          "Origin",
+			},
+		},
+		{
+			Name:   "RequestDeniedOrigin",
+			Method: "GET",
+			Path:   "/users",
+			Origin: "https://example.org",
+			Code:   http.StatusOK,
+			Expected: map[string]string{
+				"Access-Control-Allow-Origin": "",
+			},
+		},
+		{
+			Name:   "SameOrigin",
+			Method: "GET",
+			Path:   "/users",
+			Code:   http.StatusOK,
+			Expected: map[string]string{
+				"Vary": "",
+			},
+		},
+		{
+			Name:   "MethodNotAllowed",
+			Method: "PUT",
+			Path:   "/users",
+			Origin: "https://example.com",
+			Code:   http.StatusMethodNotAllowed,
+			Expected: map[string]string{
+				"Access-Control-Allow-Origin": "https://example.com",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.Method, tc.Path, nil)
+			if tc.Origin != "" {
+				r.Header.Set("Origin", tc.Origin)
+			}
+			if tc.Request != "" {
+				r.Header.Set("Access-Control-Request-Method", tc.Request)
+			}
+			if tc.Headers != "" {
+				r.Header.Set("Access-Control-Request-Headers", tc.Headers)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			if w.Code != tc.Code {
+				t.Fatal(newStatusError(w.Code, tc.Code))
+			}
+			for key, expected := range tc.Expected {
+				if got := w.Header().Get(key); got != expected {
+					t.Fatalf("failed: got %s %q, expected %q", key, got, expected)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Origins []string
+	}{
+		{Name: "Empty"},
+		{Name: "Wildcard", Origins: []string{"*"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter()
+			router.UseCORS(mux.CORSOptions{AllowedOrigins: tc.Origins, AllowCredentials: true})
+			router.HandleFunc("/users", okHandler).Methods("GET")
+
+			for _, method := range []string{"GET", "OPTIONS"} {
+				r := httptest.NewRequest(method, "/users", nil)
+				r.Header.Set("Origin", "https://evil.example")
+				r.Header.Set("Access-Control-Request-Method", "GET")
+				w := httptest.NewRecorder()
+
+				router.ServeHTTP(w, r)
+
+				if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+					t.Fatalf("failed: %s got origin %q, expected %q", method, got, "*")
+				}
+				if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+					t.Fatalf("failed: %s got credentials %q, expected none", method, got)
+				}
+			}
+		})
+	}
+}
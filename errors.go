@@ -1,14 +1,37 @@
 package mux
 
-import "net/http"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
 
 // ErrorHandlerFunc handles error returned by `Handler`.
 type ErrorHandlerFunc func(err error, w http.ResponseWriter, r *http.Request)
 
 func basicErrorFunc(err error, w http.ResponseWriter, r *http.Request) {
+	var e *HTTPError
+	if errors.As(err, &e) {
+		e.writeHeader(w)
+	}
 	http.Error(w, err.Error(), http.StatusInternalServerError)
 }
 
+func writeJSON(w http.ResponseWriter, code int, contentType string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code)
+	w.Write(data)
+}
+
 // Wrapper defines route wrapping methods and error handling
 // for `Router` and `Route`.
 type Wrapper interface {
@@ -19,15 +42,87 @@ type Wrapper interface {
 	HandleError(error, http.ResponseWriter, *http.Request)
 }
 
+// WrapperOption configures wrapper returned by `NewDefaultWrapper`.
+type WrapperOption func(*defaultWrapper)
+
+// WithErrorIDs stamps every handled error with request ID. ID is taken from
+// `X-Request-ID` request header or generated by fn. If fn is nil,
+// `NewRequestID` is used. ID is set as `HTTPError.ErrorID` and written to
+// `X-Request-ID` response header. Errors other than `HTTPError` are turned
+// into internal server error. Without this option, errors of requests passed
+// through `RequestIDMiddleware` keep their type: only `HTTPError` is stamped,
+// other errors get the ID in response header only.
+func WithErrorIDs(fn func() string) WrapperOption {
+	if fn == nil {
+		fn = NewRequestID
+	}
+	return func(wr *defaultWrapper) { wr.newErrorID = fn }
+}
+
+// WithErrorRegistry resolves every handled error through reg, so
+// `ErrorHandlerFunc` receives `HTTPError`.
+func WithErrorRegistry(reg *ErrorRegistry) WrapperOption {
+	return func(wr *defaultWrapper) { wr.registry = reg }
+}
+
+// WithPanicRecovery recovers panics raised by `HandlerFunc` and
+// `MiddlewareFunc` and passes them to `HandleError` as internal server error
+// with `PanicError` attached as `InternalError`.
+func WithPanicRecovery() WrapperOption {
+	return func(wr *defaultWrapper) { wr.recoverPanics = true }
+}
+
+// WithRepanicOnAbort makes recovery re-panic `http.ErrAbortHandler`,
+// so net/http can abort response as usual.
+func WithRepanicOnAbort() WrapperOption {
+	return func(wr *defaultWrapper) { wr.repanicOnAbort = true }
+}
+
+// PanicError holds recovered panic value and stack trace.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error implements error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns recovered value if it is an error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
 // NewDefaultWrapper returns a new default wrapper.
-func NewDefaultWrapper(fn ErrorHandlerFunc) Wrapper { return &defaultWrapper{fn} }
+func NewDefaultWrapper(fn ErrorHandlerFunc, opts ...WrapperOption) Wrapper {
+	wr := &defaultWrapper{ErrorHandler: fn}
+	for _, opt := range opts {
+		opt(wr)
+	}
+	return wr
+}
 
-type defaultWrapper struct{ ErrorHandler ErrorHandlerFunc }
+type defaultWrapper struct {
+	ErrorHandler   ErrorHandlerFunc
+	registry       *ErrorRegistry
+	newErrorID     func() string
+	logger         ErrorLogger
+	recoverPanics  bool
+	repanicOnAbort bool
+}
 
 func (wr *defaultWrapper) ServeHandler(fn HandlerFunc) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := fn(w, r); err != nil {
-			wr.HandleError(err, w, r)
+		rw := wrapResponseWriter(w)
+		if wr.recoverPanics {
+			defer wr.recoverPanic(rw, r)
+		}
+		if err := fn(rw.writer(), r); err != nil {
+			wr.handle(err, rw, r)
 		}
 	}
 }
@@ -39,9 +134,14 @@ func (wr *defaultWrapper) HandlerFunc(fn HandlerFunc) http.HandlerFunc {
 func (wr *defaultWrapper) ServeMiddleware(mwf MiddlewareFunc) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rw := wrapResponseWriter(w)
+			if wr.recoverPanics {
+				defer wr.recoverPanic(rw, r)
+			}
+			w = rw.writer()
 			ctx, err := mwf(w, r)
 			if err != nil {
-				wr.HandleError(err, w, r)
+				wr.handle(err, rw, r)
 				return
 			}
 			if ctx != nil {
@@ -56,6 +156,96 @@ func (wr *defaultWrapper) MiddlewareFunc(mwf MiddlewareFunc) func(next http.Hand
 	return wr.ServeMiddleware(mwf)
 }
 
+func (wr *defaultWrapper) recoverPanic(w http.ResponseWriter, r *http.Request) {
+	v := recover()
+	if v == nil {
+		return
+	}
+	if wr.repanicOnAbort && v == http.ErrAbortHandler {
+		panic(v)
+	}
+	code := http.StatusInternalServerError
+	err := NewHTTPError(code, http.StatusText(code)).
+		WithInternalError(&PanicError{Value: v, Stack: debug.Stack()})
+	wr.handle(err, w, r)
+}
+
+// handle passes err to enclosing `AroundFunc`, if any, or handles it.
+func (wr *defaultWrapper) handle(err error, w http.ResponseWriter, r *http.Request) {
+	if errors.Is(err, ErrHandled) {
+		return
+	}
+	if slot := errorSlotFrom(r); slot != nil {
+		slot.err = err
+		return
+	}
+	wr.HandleError(err, w, r)
+}
+
 func (wr *defaultWrapper) HandleError(err error, w http.ResponseWriter, r *http.Request) {
+	if errors.Is(err, ErrHandled) {
+		return
+	}
+	if scoped := scopedWrapper(r); scoped != nil && scoped != Wrapper(wr) {
+		ctx := context.WithValue(r.Context(), wrapperContextKey, nil)
+		scoped.HandleError(err, w, r.WithContext(ctx))
+		return
+	}
+	if tw, ok := w.(trackedWriter); ok && tw.tracked().Committed() {
+		w = tw.tracked().discarding()
+	}
+	if wr.registry != nil {
+		var e *HTTPError
+		if !errors.As(err, &e) {
+			err = wr.registry.Resolve(err)
+		}
+	}
+	if wr.newErrorID != nil || RequestID(r.Context()) != "" {
+		err = wr.stampErrorID(err, w, r)
+	}
+	if wr.logger != nil {
+		wr.logger.LogError(r, err)
+	}
 	wr.ErrorHandler(err, w, r)
 }
+
+// requestID returns ID stored by `RequestIDMiddleware`, taken from request
+// header or generated.
+func (wr *defaultWrapper) requestID(r *http.Request) string {
+	if id := RequestID(r.Context()); id != "" {
+		return id
+	}
+	if id := r.Header.Get(RequestIDHeader); validRequestID(id) {
+		return id
+	}
+	return wr.newErrorID()
+}
+
+// stampErrorID sets request ID as `HTTPError.ErrorID` and response header.
+// With `WithErrorIDs` other errors are turned into `HTTPError`, otherwise
+// only `HTTPError` itself is stamped and error type is kept.
+func (wr *defaultWrapper) stampErrorID(err error, w http.ResponseWriter, r *http.Request) error {
+	var e *HTTPError
+	switch {
+	case wr.newErrorID != nil:
+		if !errors.As(err, &e) {
+			e = toHTTPError(err)
+			err = e
+		}
+	default:
+		e, _ = err.(*HTTPError)
+	}
+	if e == nil {
+		w.Header().Set(RequestIDHeader, wr.requestID(r))
+		return err
+	}
+	if e.ErrorID == "" {
+		// Stamp a copy, so errors shared between requests are left intact.
+		cp := *e
+		cp.ErrorID = wr.requestID(r)
+		e = &cp
+		err = e
+	}
+	w.Header().Set(RequestIDHeader, e.ErrorID)
+	return err
+}
@@ -0,0 +1,36 @@
+package mux
+
+// FieldError describes a single field violation.
+type FieldError struct {
+	Path    string `json:"path" xml:"path"`
+	Code    string `json:"code" xml:"code"`
+	Message string `json:"message" xml:"message"`
+}
+
+// Error implements error interface.
+func (e FieldError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// FieldErrors collects field violations to be returned as one error.
+type FieldErrors []FieldError
+
+// Add appends a new field violation.
+func (fe *FieldErrors) Add(path, code, message string) {
+	*fe = append(*fe, FieldError{Path: path, Code: code, Message: message})
+}
+
+// Len returns number of collected violations.
+func (fe FieldErrors) Len() int { return len(fe) }
+
+// Err returns nil if no violations were collected, otherwise `HTTPError`
+// with given code, message and violations as `Details`.
+func (fe FieldErrors) Err(code int, message string) error {
+	if len(fe) == 0 {
+		return nil
+	}
+	return NewHTTPError(code, message).WithDetails(fe...)
+}
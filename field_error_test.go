@@ -0,0 +1,70 @@
+package mux_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func ExampleFieldErrors() {
+	var violations mux.FieldErrors
+	violations.Add("email", "required", "Email is required")
+	violations.Add("items[0].quantity", "min", "Quantity must be at least 1")
+
+	err := violations.Err(http.StatusUnprocessableEntity, "Validation Failed")
+	data, _ := json.Marshal(err)
+	fmt.Println(string(data))
+
+	// Output:
+	// {"code":422,"message":"Validation Failed","details":[{"path":"email","code":"required","message":"Email is required"},{"path":"items[0].quantity","code":"min","message":"Quantity must be at least 1"}]}
+}
+
+func TestFieldErrorsEmpty(t *testing.T) {
+	var violations mux.FieldErrors
+	if err := violations.Err(http.StatusBadRequest, "Bad Request"); err != nil {
+		t.Fatalf("got %v, expected nil", err)
+	}
+}
+
+func TestHTTPErrorDetailsRoundTrip(t *testing.T) {
+	details := []mux.FieldError{
+		{Path: "name", Code: "required", Message: "Name is required"},
+		{Path: "age", Code: "max", Message: "Age must be at most 150"},
+	}
+
+	testCases := []struct {
+		Name string
+		Err  *mux.HTTPError
+	}{
+		{
+			Name: "Default",
+			Err:  mux.NewHTTPError(http.StatusBadRequest, "Bad Request").WithDetails(details...),
+		},
+		{
+			Name: "Problem",
+			Err:  mux.NewHTTPError(http.StatusBadRequest, "Bad Request").WithDetails(details...).WithProblemDetails(true),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			data, err := json.Marshal(tc.Err)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var e mux.HTTPError
+			if err := json.Unmarshal(data, &e); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(e.Details, details) {
+				t.Fatal(errors.New("details mismatch: " + string(data)))
+			}
+		})
+	}
+}
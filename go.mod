@@ -1,5 +1,5 @@
 module github.com/danikarik/mux
 
-go 1.12
+go 1.21
 
 require github.com/gorilla/mux v1.7.3
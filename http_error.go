@@ -2,9 +2,38 @@ package mux
 
 import (
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"net/http"
 )
 
+// Sentinel errors matching any `HTTPError` with the same status code.
+// They can be returned from handlers as is.
+var (
+	ErrBadRequest            error = statusError(http.StatusBadRequest)
+	ErrUnauthorized          error = statusError(http.StatusUnauthorized)
+	ErrForbidden             error = statusError(http.StatusForbidden)
+	ErrNotFound              error = statusError(http.StatusNotFound)
+	ErrMethodNotAllowed      error = statusError(http.StatusMethodNotAllowed)
+	ErrNotAcceptable         error = statusError(http.StatusNotAcceptable)
+	ErrConflict              error = statusError(http.StatusConflict)
+	ErrGone                  error = statusError(http.StatusGone)
+	ErrPreconditionFailed    error = statusError(http.StatusPreconditionFailed)
+	ErrRequestEntityTooLarge error = statusError(http.StatusRequestEntityTooLarge)
+	ErrUnsupportedMediaType  error = statusError(http.StatusUnsupportedMediaType)
+	ErrUnprocessableEntity   error = statusError(http.StatusUnprocessableEntity)
+	ErrTooManyRequests       error = statusError(http.StatusTooManyRequests)
+	ErrInternalServerError   error = statusError(http.StatusInternalServerError)
+	ErrNotImplemented        error = statusError(http.StatusNotImplemented)
+	ErrBadGateway            error = statusError(http.StatusBadGateway)
+	ErrServiceUnavailable    error = statusError(http.StatusServiceUnavailable)
+	ErrGatewayTimeout        error = statusError(http.StatusGatewayTimeout)
+)
+
+type statusError int
+
+func (s statusError) Error() string { return http.StatusText(int(s)) }
+
 // HTTPError holds http error info.
 type HTTPError struct {
 	Code            int
@@ -13,14 +42,23 @@ type HTTPError struct {
 	InternalMessage string
 	ErrorID         string
 	ShowError       bool
+	ProblemDetails  bool
+	Type            string
+	Title           string
+	Instance        string
+	Extensions      map[string]interface{}
+	Details         []FieldError
+	Header          http.Header
 }
 
 type httpError struct {
-	Code            int    `json:"code"`
-	Message         string `json:"message"`
-	InternalError   string `json:"internalError,omitempty"`
-	InternalMessage string `json:"internalMessage,omitempty"`
-	ErrorID         string `json:"id,omitempty"`
+	XMLName         xml.Name     `json:"-" xml:"error"`
+	Code            int          `json:"code" xml:"code"`
+	Message         string       `json:"message" xml:"message"`
+	InternalError   string       `json:"internalError,omitempty" xml:"internalError,omitempty"`
+	InternalMessage string       `json:"internalMessage,omitempty" xml:"internalMessage,omitempty"`
+	ErrorID         string       `json:"id,omitempty" xml:"id,omitempty"`
+	Details         []FieldError `json:"details,omitempty" xml:"detail,omitempty"`
 }
 
 // NewHTTPError returns a new instance of `HTTPError`.
@@ -35,6 +73,34 @@ func NewHTTPError(code int, message string, opts ...func(e *HTTPError)) *HTTPErr
 	return err
 }
 
+// toHTTPError returns `HTTPError` found in err chain or wraps err into
+// internal server error. Status sentinels are turned into `HTTPError`
+// with the same code.
+func toHTTPError(err error) *HTTPError {
+	var e *HTTPError
+	if errors.As(err, &e) {
+		return e
+	}
+	var s statusError
+	if errors.As(err, &s) {
+		e = NewHTTPError(int(s), s.Error())
+		if err != error(s) {
+			e.InternalError = err
+		}
+		return e
+	}
+	code := http.StatusInternalServerError
+	return NewHTTPError(code, http.StatusText(code)).WithInternalError(err)
+}
+
+// status returns `Code` if it is a valid http status code.
+func (e *HTTPError) status() int {
+	if e.Code < 100 || e.Code > 999 {
+		return http.StatusInternalServerError
+	}
+	return e.Code
+}
+
 // Error implements error interface.
 func (e *HTTPError) Error() string {
 	if e.InternalMessage != "" {
@@ -43,6 +109,18 @@ func (e *HTTPError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns `InternalError`.
+func (e *HTTPError) Unwrap() error {
+	return e.InternalError
+}
+
+// Is reports whether target is a status sentinel with the same code,
+// e.g. `errors.Is(err, mux.ErrNotFound)`.
+func (e *HTTPError) Is(target error) bool {
+	s, ok := target.(statusError)
+	return ok && int(s) == e.Code
+}
+
 // WithInternalMessage updates `InternalMessage` field.
 func (e *HTTPError) WithInternalMessage(message string) *HTTPError {
 	e.InternalMessage = message
@@ -67,22 +145,99 @@ func (e *HTTPError) WithShowError(flag bool) *HTTPError {
 	return e
 }
 
+// WithDetails appends field violations to `Details` field.
+func (e *HTTPError) WithDetails(details ...FieldError) *HTTPError {
+	e.Details = append(e.Details, details...)
+	return e
+}
+
+// WithFieldError appends a single field violation to `Details` field.
+func (e *HTTPError) WithFieldError(path, code, message string) *HTTPError {
+	return e.WithDetails(FieldError{Path: path, Code: code, Message: message})
+}
+
+// WithHeader adds response header written along with error,
+// e.g. `Retry-After` or `WWW-Authenticate`.
+func (e *HTTPError) WithHeader(key, value string) *HTTPError {
+	if e.Header == nil {
+		e.Header = make(http.Header)
+	}
+	e.Header.Add(key, value)
+	return e
+}
+
+// writeHeader copies `Header` into response headers.
+func (e *HTTPError) writeHeader(w http.ResponseWriter) {
+	for k, vv := range e.Header {
+		w.Header()[k] = append([]string(nil), vv...)
+	}
+}
+
+// WithProblemDetails updates `ProblemDetails` field.
+func (e *HTTPError) WithProblemDetails(flag bool) *HTTPError {
+	e.ProblemDetails = flag
+	return e
+}
+
+// WithType updates `Type` field.
+func (e *HTTPError) WithType(uri string) *HTTPError {
+	e.Type = uri
+	return e
+}
+
+// WithTitle updates `Title` field.
+func (e *HTTPError) WithTitle(title string) *HTTPError {
+	e.Title = title
+	return e
+}
+
+// WithInstance updates `Instance` field.
+func (e *HTTPError) WithInstance(uri string) *HTTPError {
+	e.Instance = uri
+	return e
+}
+
+// WithExtension adds problem details extension member.
+func (e *HTTPError) WithExtension(key string, value interface{}) *HTTPError {
+	if e.Extensions == nil {
+		e.Extensions = make(map[string]interface{})
+	}
+	e.Extensions[key] = value
+	return e
+}
+
 // MarshalJSON implemenets `json.Marshal`.
 func (e *HTTPError) MarshalJSON() ([]byte, error) {
+	if e.ProblemDetails {
+		return e.marshalProblem()
+	}
+	return json.Marshal(e.data())
+}
+
+// MarshalXML implements `xml.Marshal`.
+func (e *HTTPError) MarshalXML(enc *xml.Encoder, start xml.StartElement) error {
+	return enc.Encode(e.data())
+}
+
+func (e *HTTPError) data() *httpError {
 	data := &httpError{
 		Code:            e.Code,
 		Message:         e.Message,
 		ErrorID:         e.ErrorID,
 		InternalMessage: e.InternalMessage,
+		Details:         e.Details,
 	}
 	if e.ShowError && e.InternalError != nil {
 		data.InternalError = e.InternalError.Error()
 	}
-	return json.Marshal(data)
+	return data
 }
 
 // UnmarshalJSON implements `json.Unmarshal`.
 func (e *HTTPError) UnmarshalJSON(b []byte) error {
+	if isProblem(b) {
+		return e.unmarshalProblem(b)
+	}
 	var data httpError
 	err := json.Unmarshal(b, &data)
 	if err != nil {
@@ -92,6 +247,7 @@ func (e *HTTPError) UnmarshalJSON(b []byte) error {
 	e.Message = data.Message
 	e.ErrorID = data.ErrorID
 	e.InternalMessage = data.InternalMessage
+	e.Details = data.Details
 	if data.InternalError != "" {
 		e.ShowError = true
 		e.InternalError = errors.New(data.InternalError)
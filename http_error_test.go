@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"testing"
 
 	"github.com/danikarik/mux"
 )
@@ -36,3 +37,71 @@ func ExampleHTTPError() {
 	// {"code":500,"message":"Server Error","internalError":"Unexpected Error","internalMessage":"Failed","id":"123"}
 	// {"code":401,"message":"Unauthorized","internalMessage":"Failed","id":"456"}
 }
+
+func TestHTTPErrorIs(t *testing.T) {
+	errNoRows := errors.New("no rows in result set")
+
+	testCases := []struct {
+		Name     string
+		Err      error
+		Target   error
+		Expected bool
+	}{
+		{
+			Name:     "InternalError",
+			Err:      mux.NewHTTPError(http.StatusNotFound, "Not Found").WithInternalError(errNoRows),
+			Target:   errNoRows,
+			Expected: true,
+		},
+		{
+			Name:     "Wrapped",
+			Err:      fmt.Errorf("load user: %w", mux.NewHTTPError(http.StatusNotFound, "Not Found").WithInternalError(errNoRows)),
+			Target:   errNoRows,
+			Expected: true,
+		},
+		{
+			Name:     "Sentinel",
+			Err:      mux.NewHTTPError(http.StatusNotFound, "User not found"),
+			Target:   mux.ErrNotFound,
+			Expected: true,
+		},
+		{
+			Name:     "WrappedSentinel",
+			Err:      fmt.Errorf("create user: %w", mux.NewHTTPError(http.StatusConflict, "Already exists")),
+			Target:   mux.ErrConflict,
+			Expected: true,
+		},
+		{
+			Name:     "OtherSentinel",
+			Err:      mux.NewHTTPError(http.StatusNotFound, "User not found"),
+			Target:   mux.ErrConflict,
+			Expected: false,
+		},
+		{
+			Name:     "PlainError",
+			Err:      errNoRows,
+			Target:   mux.ErrInternalServerError,
+			Expected: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			if got := errors.Is(tc.Err, tc.Target); got != tc.Expected {
+				t.Fatalf("got %v, expected %v", got, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestHTTPErrorAs(t *testing.T) {
+	err := fmt.Errorf("handler: %w", mux.NewHTTPError(http.StatusForbidden, "Forbidden"))
+
+	var e *mux.HTTPError
+	if !errors.As(err, &e) {
+		t.Fatal("expected HTTPError in chain")
+	}
+	if e.Code != http.StatusForbidden {
+		t.Fatal(newStatusError(e.Code, http.StatusForbidden))
+	}
+}
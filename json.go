@@ -0,0 +1,137 @@
+package mux
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// JSONOption configures handler returned by `JSON`.
+type JSONOption func(*jsonConfig)
+
+type jsonConfig struct {
+	status       int
+	strict       bool
+	maxBodyBytes int64
+}
+
+// JSONStatus sets response status code. Default is 200.
+func JSONStatus(code int) JSONOption {
+	return func(c *jsonConfig) { c.status = code }
+}
+
+// JSONStrict rejects request bodies with unknown fields.
+func JSONStrict() JSONOption {
+	return func(c *jsonConfig) { c.strict = true }
+}
+
+// JSONMaxBodyBytes limits size of request body.
+func JSONMaxBodyBytes(n int64) JSONOption {
+	return func(c *jsonConfig) { c.maxBodyBytes = n }
+}
+
+// JSON returns `HandlerFunc` decoding request body into Req, calling fn and
+// encoding its result as JSON. Empty body leaves Req with zero value.
+// Decoding errors are returned as `HTTPError` with status 400, decoded Req
+// is then checked by `Validate`.
+func JSON[Req, Resp any](fn func(ctx context.Context, req Req) (Resp, error), opts ...JSONOption) HandlerFunc {
+	cfg := jsonConfig{status: http.StatusOK}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var req Req
+		if err := decodeJSON(w, r, &req, &cfg); err != nil {
+			return err
+		}
+		if err := Validate(&req); err != nil {
+			return err
+		}
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			return err
+		}
+		if cfg.status == http.StatusNoContent {
+			w.WriteHeader(cfg.status)
+			return nil
+		}
+		data, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(cfg.status)
+		w.Write(data)
+		return nil
+	}
+}
+
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}, cfg *jsonConfig) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && !isJSONContentType(ct) {
+		code := http.StatusUnsupportedMediaType
+		return NewHTTPError(code, fmt.Sprintf("Content type %q is not supported", ct))
+	}
+
+	body := r.Body
+	if cfg.maxBodyBytes > 0 {
+		body = http.MaxBytesReader(w, body, cfg.maxBodyBytes)
+	}
+	dec := json.NewDecoder(body)
+	if cfg.strict {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(v)
+	if err == io.EOF {
+		return nil
+	}
+	if err == nil && dec.More() {
+		err = errors.New("body must contain a single JSON value")
+	}
+	if err != nil {
+		return jsonDecodeError(err)
+	}
+	return nil
+}
+
+func jsonDecodeError(err error) *HTTPError {
+	e := NewHTTPError(http.StatusBadRequest, "Invalid JSON body").WithInternalError(err)
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var sizeErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &sizeErr):
+		e.Code = http.StatusRequestEntityTooLarge
+		e.Message = fmt.Sprintf("Body must not be larger than %d bytes", sizeErr.Limit)
+	case errors.As(err, &syntaxErr):
+		e.Message = fmt.Sprintf("Malformed JSON body at position %d", syntaxErr.Offset)
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		e.Message = "Malformed JSON body"
+	case errors.As(err, &typeErr):
+		path := typeErr.Field
+		if path == "" {
+			path = "body"
+		}
+		e.WithFieldError(path, "type", fmt.Sprintf("must be %s", typeErr.Type))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		e.WithFieldError(field, "unknown", "unknown field")
+	}
+	return e
+}
+
+func isJSONContentType(ct string) bool {
+	mediaType, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
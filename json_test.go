@@ -0,0 +1,137 @@
+package mux_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+type createUserRequest struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+type createUserResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func createUser(ctx context.Context, req createUserRequest) (createUserResponse, error) {
+	if req.Name == "taken" {
+		return createUserResponse{}, mux.ErrConflict
+	}
+	return createUserResponse{ID: 1, Name: req.Name}, nil
+}
+
+func TestJSON(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Body        string
+		ContentType string
+		Code        int
+		Expected    string
+	}{
+		{
+			Name:     "Created",
+			Body:     `{"name":"john","age":30}`,
+			Code:     http.StatusCreated,
+			Expected: `{"id":1,"name":"john"}`,
+		},
+		{
+			Name:     "Empty",
+			Code:     http.StatusCreated,
+			Expected: `{"id":1,"name":""}`,
+		},
+		{
+			Name:     "ServiceError",
+			Body:     `{"name":"taken"}`,
+			Code:     http.StatusConflict,
+			Expected: `{"code":409,"message":"Conflict"}`,
+		},
+		{
+			Name:     "Malformed",
+			Body:     `{"name":`,
+			Code:     http.StatusBadRequest,
+			Expected: `{"code":400,"message":"Malformed JSON body"}`,
+		},
+		{
+			Name:     "WrongType",
+			Body:     `{"name":"john","age":"thirty"}`,
+			Code:     http.StatusBadRequest,
+			Expected: `{"code":400,"message":"Invalid JSON body","details":[{"path":"age","code":"type","message":"must be int"}]}`,
+		},
+		{
+			Name:     "UnknownField",
+			Body:     `{"name":"john","email":"john@example.com"}`,
+			Code:     http.StatusBadRequest,
+			Expected: `{"code":400,"message":"Invalid JSON body","details":[{"path":"email","code":"unknown","message":"unknown field"}]}`,
+		},
+		{
+			Name:        "UnsupportedMediaType",
+			Body:        `name=john`,
+			ContentType: "application/x-www-form-urlencoded",
+			Code:        http.StatusUnsupportedMediaType,
+			Expected:    `{"code":415,"message":"Content type \"application/x-www-form-urlencoded\" is not supported"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+			router.Path("/users").Methods("POST").
+				HandlerFunc(mux.JSON(createUser, mux.JSONStatus(http.StatusCreated), mux.JSONStrict()))
+
+			r := httptest.NewRequest("POST", "/users", strings.NewReader(tc.Body))
+			if tc.ContentType != "" {
+				r.Header.Set("Content-Type", tc.ContentType)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if strings.TrimSpace(string(data)) != tc.Expected {
+				t.Fatalf("failed: got %s, expected %s", string(data), tc.Expected)
+			}
+		})
+	}
+}
+
+func TestJSONMaxBodyBytes(t *testing.T) {
+	router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+	router.HandleFunc("/users", mux.JSON(createUser, mux.JSONMaxBodyBytes(8)))
+
+	r := httptest.NewRequest("POST", "/users", strings.NewReader(`{"name":"john"}`))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatal(newStatusError(resp.StatusCode, http.StatusRequestEntityTooLarge))
+	}
+
+	var e mux.HTTPError
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Message != "Body must not be larger than 8 bytes" {
+		t.Fatalf("got message %q", e.Message)
+	}
+}
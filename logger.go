@@ -0,0 +1,80 @@
+package mux
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	gorillamux "github.com/gorilla/mux"
+)
+
+// ErrorLogger logs errors passed to `Wrapper.HandleError`.
+type ErrorLogger interface {
+	LogError(r *http.Request, err error)
+}
+
+// ErrorLoggerFunc is an adapter to use ordinary function as `ErrorLogger`.
+type ErrorLoggerFunc func(r *http.Request, err error)
+
+// LogError calls fn(r, err).
+func (fn ErrorLoggerFunc) LogError(r *http.Request, err error) { fn(r, err) }
+
+// WithErrorLogger passes every handled error to l before `ErrorHandlerFunc`.
+func WithErrorLogger(l ErrorLogger) WrapperOption {
+	return func(wr *defaultWrapper) { wr.logger = l }
+}
+
+// NewSlogErrorLogger returns `ErrorLogger` writing to l. Server errors are
+// logged at error level, client errors at debug level. `InternalError` is
+// logged only if `ShowError` is set.
+func NewSlogErrorLogger(l *slog.Logger) ErrorLogger {
+	return &slogErrorLogger{l}
+}
+
+type slogErrorLogger struct{ logger *slog.Logger }
+
+func (l *slogErrorLogger) LogError(r *http.Request, err error) {
+	e := toHTTPError(err)
+	code := e.status()
+
+	level := slog.LevelDebug
+	if code >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	ctx := r.Context()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", r.Method),
+		slog.Int("status", code),
+	}
+	if route := gorillamux.CurrentRoute(r); route != nil {
+		if name := route.GetName(); name != "" {
+			attrs = append(attrs, slog.String("route", name))
+		}
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			attrs = append(attrs, slog.String("path", tpl))
+		}
+	}
+	if e.ErrorID != "" {
+		attrs = append(attrs, slog.String("error_id", e.ErrorID))
+	}
+	attrs = append(attrs, slog.Any("errors", errorChain(err)))
+	l.logger.LogAttrs(ctx, level, "request failed", attrs...)
+}
+
+// errorChain returns messages of err and errors it wraps. `InternalError` of
+// `HTTPError` is skipped unless `ShowError` is set.
+func errorChain(err error) []string {
+	var chain []string
+	for err != nil {
+		chain = append(chain, err.Error())
+		if e, ok := err.(*HTTPError); ok && !e.ShowError {
+			break
+		}
+		err = errors.Unwrap(err)
+	}
+	return chain
+}
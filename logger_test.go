@@ -0,0 +1,85 @@
+package mux_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func TestSlogErrorLogger(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Err      error
+		Level    string
+		Status   float64
+		Expected []interface{}
+	}{
+		{
+			Name:     "ServerError",
+			Err:      mux.NewHTTPError(http.StatusBadGateway, "Bad Gateway").WithInternalError(errors.New("secret dsn")),
+			Level:    "ERROR",
+			Status:   http.StatusBadGateway,
+			Expected: []interface{}{"Bad Gateway"},
+		},
+		{
+			Name: "ShowError",
+			Err: mux.NewHTTPError(http.StatusInternalServerError, "Server Error").
+				WithInternalError(errors.New("timeout")).
+				WithShowError(true),
+			Level:    "ERROR",
+			Status:   http.StatusInternalServerError,
+			Expected: []interface{}{"Server Error", "timeout"},
+		},
+		{
+			Name:     "ClientError",
+			Err:      fmt.Errorf("find user: %w", mux.NewHTTPError(http.StatusNotFound, "Not Found")),
+			Level:    "DEBUG",
+			Status:   http.StatusNotFound,
+			Expected: []interface{}{"find user: Not Found", "Not Found"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+			router := mux.NewRouter(func(r *mux.Router) {
+				r.Wrapper = mux.NewDefaultWrapper(mux.NegotiateErrorFunc, mux.WithErrorLogger(mux.NewSlogErrorLogger(logger)))
+			})
+			router.HandleFunc("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+				return tc.Err
+			}).Methods("GET").Name("user")
+
+			r := httptest.NewRequest("GET", "/users/1", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+			if entry["level"] != tc.Level {
+				t.Fatalf("got level %v, expected %s", entry["level"], tc.Level)
+			}
+			if entry["status"] != tc.Status {
+				t.Fatalf("got status %v, expected %v", entry["status"], tc.Status)
+			}
+			if entry["route"] != "user" || entry["path"] != "/users/{id}" || entry["method"] != "GET" {
+				t.Fatalf("got route info %v", entry)
+			}
+			if !reflect.DeepEqual(entry["errors"], tc.Expected) {
+				t.Fatalf("got errors %v, expected %v", entry["errors"], tc.Expected)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package mux
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	gorillamux "github.com/gorilla/mux"
@@ -10,6 +11,11 @@ import (
 // MiddlewareFunc wraps standard `http.Handler` middleware style with context and error.
 type MiddlewareFunc func(w http.ResponseWriter, r *http.Request) (context.Context, error)
 
+// ErrHandled is returned by `MiddlewareFunc` or `HandlerFunc` which has
+// completed the response, e.g. served cached content. It stops the chain
+// without error handling.
+var ErrHandled = errors.New("mux: response handled")
+
 // Use appends a MiddlewareFunc to the chain.
 func (r *Router) Use(mwf ...MiddlewareFunc) {
 	middlewares := []gorillamux.MiddlewareFunc{}
@@ -23,3 +29,40 @@ func (r *Router) Use(mwf ...MiddlewareFunc) {
 func (r *Router) UseBypass(mwf ...gorillamux.MiddlewareFunc) {
 	r.mux.Use(mwf...)
 }
+
+// Use appends a MiddlewareFunc to the route's chain. Route middlewares are
+// wrapped with route's `Wrapper` and run after router middlewares, in order
+// they were added.
+func (r *Route) Use(mwf ...MiddlewareFunc) *Route {
+	mws := make([]routeMiddleware, 0, len(mwf))
+	for _, fn := range mwf {
+		mws = append(mws, routeMiddleware{fn: fn})
+	}
+	return r.use(mws)
+}
+
+// UseBypass appends a gorilla's `mux.MiddlewareFunc` to the route's chain.
+// See Route.Use().
+func (r *Route) UseBypass(mwf ...gorillamux.MiddlewareFunc) *Route {
+	mws := make([]routeMiddleware, 0, len(mwf))
+	for _, fn := range mwf {
+		mws = append(mws, routeMiddleware{bypass: fn})
+	}
+	return r.use(mws)
+}
+
+func (r *Route) use(mws []routeMiddleware) *Route {
+	if r.table == nil {
+		r.table = newRouteTable()
+	}
+	r.table.use(r.route, r.Wrapper, mws...)
+	return r.derive(r.route)
+}
+
+// GetMiddlewares returns middlewares of the route in order they run.
+func (r *Route) GetMiddlewares() []gorillamux.MiddlewareFunc {
+	if r.table == nil {
+		return nil
+	}
+	return r.table.middlewares(r.route)
+}
@@ -192,3 +192,123 @@ func TestMiddlewareContextBypass(t *testing.T) {
 		})
 	}
 }
+
+func TestRouteMiddleware(t *testing.T) {
+	teapot := func(err error, w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+	deny := func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		return nil, errors.New("forbidden")
+	}
+
+	router := mux.NewRouter()
+	router.Use(middlewareContextFunc(1))
+	router.HandleFunc("/public", contextHandler)
+	router.HandleFunc("/private", contextHandler).Use(middlewareContextFunc(2))
+	router.HandleFunc("/bypass", contextHandler).UseBypass(middlewareContextFuncForBypass(3))
+	router.Path("/before").Use(middlewareContextFunc(4)).HandlerFunc(contextHandler)
+	router.HandleFunc("/denied", contextHandler).Use(deny).WithErrorHandler(teapot)
+
+	testCases := []struct {
+		Name     string
+		Path     string
+		Code     int
+		Expected string
+	}{
+		{Name: "Router", Path: "/public", Code: http.StatusOK, Expected: "1"},
+		{Name: "Route", Path: "/private", Code: http.StatusOK, Expected: "2"},
+		{Name: "Bypass", Path: "/bypass", Code: http.StatusOK, Expected: "3"},
+		{Name: "BeforeHandler", Path: "/before", Code: http.StatusOK, Expected: "4"},
+		{Name: "RouteWrapper", Path: "/denied", Code: http.StatusTeapot},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tc.Path, nil))
+
+			if w.Code != tc.Code {
+				t.Fatal(newStatusError(w.Code, tc.Code))
+			}
+			if w.Code == http.StatusOK && w.Body.String() != tc.Expected {
+				t.Fatalf("failed: got %s, expected %s", w.Body.String(), tc.Expected)
+			}
+		})
+	}
+}
+
+func TestRouteGetMiddlewares(t *testing.T) {
+	router := mux.NewRouter()
+	route := router.HandleFunc("/", contextHandler).
+		Use(middlewareContextFunc(1), middlewareContextFunc(2)).
+		UseBypass(middlewareContextFuncForBypass(3))
+
+	if n := len(route.GetMiddlewares()); n != 3 {
+		t.Fatalf("failed: got %d middlewares, expected 3", n)
+	}
+
+	err := router.Walk(func(route *gorillamux.Route, _ *gorillamux.Router, ancestors []*gorillamux.Route) error {
+		if n := len(router.Route(route).GetMiddlewares()); n != 3 {
+			return fmt.Errorf("failed: got %d middlewares from walk, expected 3", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMiddlewareHandled(t *testing.T) {
+	var handled int
+	cache := func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return nil, mux.ErrHandled
+		}
+		return nil, nil
+	}
+
+	router := mux.NewRouter(mux.WithErrorHandler(func(err error, w http.ResponseWriter, r *http.Request) {
+		handled++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	router.Use(cache)
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("content"))
+		return nil
+	})
+
+	testCases := []struct {
+		Name     string
+		ETag     string
+		Code     int
+		Expected string
+	}{
+		{Name: "Miss", Code: http.StatusOK, Expected: "content"},
+		{Name: "Hit", ETag: `"v1"`, Code: http.StatusNotModified},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			if tc.ETag != "" {
+				r.Header.Set("If-None-Match", tc.ETag)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			if w.Code != tc.Code {
+				t.Fatal(newStatusError(w.Code, tc.Code))
+			}
+			if w.Body.String() != tc.Expected {
+				t.Fatalf("failed: got %s, expected %s", w.Body.String(), tc.Expected)
+			}
+		})
+	}
+
+	if handled != 0 {
+		t.Fatalf("failed: error handler called %d times", handled)
+	}
+}
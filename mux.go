@@ -8,18 +8,19 @@ import (
 
 // NewRouter returns a new router instance.
 func NewRouter(opts ...func(*Router)) *Router {
-	return newRouter(gorillamux.NewRouter(), opts...)
+	return NewRouterWithMux(gorillamux.NewRouter(), opts...)
 }
 
 // NewRouterWithMux returns a new router instance with input mux.
 func NewRouterWithMux(mux *gorillamux.Router, opts ...func(*Router)) *Router {
-	return newRouter(mux, opts...)
+	return newRouter(mux, append([]func(*Router){withScopedWrappers}, opts...)...)
 }
 
 func newRouter(mux *gorillamux.Router, opts ...func(*Router)) *Router {
 	router := &Router{
 		mux:     mux,
 		Wrapper: NewDefaultWrapper(basicErrorFunc),
+		table:   newRouteTable(),
 	}
 	for _, opt := range opts {
 		opt(router)
@@ -36,6 +37,28 @@ type Router struct {
 	Wrapper                 Wrapper
 	NotFoundHandler         HandlerFunc
 	MethodNotAllowedHandler HandlerFunc
+	table                   *routeTable
+	namePrefix              string
+	cors                    *CORSOptions
+	arounds                 []gorillamux.MiddlewareFunc
+	subrouter               bool
+}
+
+// WithErrorHandler replaces error handler of router's `Wrapper`,
+// keeping other wrapper options. Custom wrapper is kept too, its handlers
+// and middlewares pass errors to fn and see them as `ErrHandled`.
+func WithErrorHandler(fn ErrorHandlerFunc) func(*Router) {
+	return func(r *Router) { r.Wrapper = withErrorHandler(r.Wrapper, fn) }
+}
+
+// withScopedWrappers registers `routeTable.scopeWrapper` as the first
+// middleware of root router.
+func withScopedWrappers(r *Router) {
+	r.mux.Use(r.table.scopeWrapper)
+}
+
+func (r *Router) newRoute(route *gorillamux.Route) *Route {
+	return NewRoute(route, routeWithWrapper(r.Wrapper), routeWithTable(r.table), routeWithNamePrefix(r.namePrefix))
 }
 
 func (r *Router) withCustomHandlers() *Router {
@@ -45,17 +68,42 @@ func (r *Router) withCustomHandlers() *Router {
 	if r.MethodNotAllowedHandler != nil {
 		r.mux.MethodNotAllowedHandler = r.Wrapper.HandlerFunc(r.MethodNotAllowedHandler)
 	}
+	if len(r.arounds) > 0 {
+		notFound := http.Handler(http.NotFoundHandler())
+		if r.NotFoundHandler != nil {
+			notFound = r.Wrapper.HandlerFunc(r.NotFoundHandler)
+		}
+		methodNotAllowed := http.Handler(http.HandlerFunc(methodNotAllowed))
+		if r.MethodNotAllowedHandler != nil {
+			methodNotAllowed = r.Wrapper.HandlerFunc(r.MethodNotAllowedHandler)
+		}
+		for i := len(r.arounds) - 1; i >= 0; i-- {
+			notFound = r.arounds[i](notFound)
+			methodNotAllowed = r.arounds[i](methodNotAllowed)
+		}
+		r.mux.NotFoundHandler = notFound
+		r.mux.MethodNotAllowedHandler = methodNotAllowed
+	}
 	return r
 }
 
+// methodNotAllowed replies like gorilla's default handler.
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
 // ServeHTTP dispatches the handler registered in the matched route.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.cors != nil && r.serveCORS(w, req) {
+		return
+	}
 	r.mux.ServeHTTP(w, req)
 }
 
-// Get returns a route registered with the given name.
+// Get returns a route registered with the given name. Name is prefixed
+// with name prefix of router, like in Route.Name().
 func (r *Router) Get(name string) *Route {
-	return NewRoute(r.mux.Get(name), routeWithWrapper(r.Wrapper))
+	return r.newRoute(r.mux.Get(r.namePrefix + name))
 }
 
 // StrictSlash defines the trailing slash behavior for new routes. The initial
@@ -79,9 +127,31 @@ func (r *Router) UseEncodedPath() *Router {
 	return r
 }
 
+// NamePrefix sets prefix for names of routes registered by the router and
+// its subrouters. Prefix is appended to one inherited from parent router.
+func (r *Router) NamePrefix(prefix string) *Router {
+	r.namePrefix += prefix
+	return r
+}
+
+// Group registers subrouter matching path prefix, with optional middlewares,
+// and calls fn to register its routes. Subrouter inherits `Wrapper`,
+// middlewares and name prefix of the router. Empty prefix groups routes by
+// middlewares only.
+func (r *Router) Group(prefix string, fn func(*Router), mws ...MiddlewareFunc) *Router {
+	route := r.NewRoute()
+	if prefix != "" {
+		route = route.PathPrefix(prefix)
+	}
+	router := route.Subrouter()
+	router.Use(mws...)
+	fn(router)
+	return router
+}
+
 // NewRoute registers an empty route.
 func (r *Router) NewRoute() *Route {
-	return NewRoute(r.mux.NewRoute(), routeWithWrapper(r.Wrapper))
+	return r.newRoute(r.mux.NewRoute())
 }
 
 // Name registers a new route with a name.
@@ -93,19 +163,19 @@ func (r *Router) Name(name string) *Route {
 // Handle registers a new route with a matcher for the URL path.
 // See Route.Path() and Route.Handler().
 func (r *Router) Handle(path string, h http.Handler) *Route {
-	return NewRoute(r.mux.Handle(path, h), routeWithWrapper(r.Wrapper))
+	return r.NewRoute().Path(path).Handler(h)
 }
 
 // HandleFunc registers a new route with a matcher for the URL path.
 // See Route.Path() and Route.HandlerFunc().
 func (r *Router) HandleFunc(path string, fn HandlerFunc) *Route {
-	return NewRoute(r.mux.HandleFunc(path, r.Wrapper.HandlerFunc(fn)), routeWithWrapper(r.Wrapper))
+	return r.NewRoute().Path(path).HandlerFunc(fn)
 }
 
 // HandleFuncBypass registers a new route with a matcher for the URL path.
 // See Route.Path() and Route.HandlerFunc().
 func (r *Router) HandleFuncBypass(path string, fn http.HandlerFunc) *Route {
-	return NewRoute(r.mux.HandleFunc(path, fn), routeWithWrapper(r.Wrapper))
+	return r.NewRoute().Path(path).HandlerFuncBypass(fn)
 }
 
 // Headers registers a new route with a matcher for request header values.
@@ -169,6 +239,16 @@ func (r *Router) Walk(walkFn gorillamux.WalkFunc) error {
 	return r.mux.Walk(walkFn)
 }
 
+// Route returns route registered in the router for gorilla's route, e.g.
+// one passed to `WalkFunc`, so its middlewares and wrapper can be inspected.
+func (r *Router) Route(route *gorillamux.Route) *Route {
+	wr := r.Wrapper
+	if entryWrapper := r.table.entryWrapper(route); entryWrapper != nil {
+		wr = entryWrapper
+	}
+	return NewRoute(route, routeWithWrapper(wr), routeWithTable(r.table), routeWithNamePrefix(r.namePrefix))
+}
+
 // Vars returns the route variables for the current request, if any.
 func Vars(r *http.Request) map[string]string {
 	return gorillamux.Vars(r)
@@ -1,6 +1,7 @@
 package mux_test
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -252,3 +253,261 @@ func TestCustomHandlers(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapperPanicRecovery(t *testing.T) {
+	panicHandler := func(w http.ResponseWriter, r *http.Request) error {
+		panic("boom")
+	}
+	panicMiddleware := func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		panic(errors.New("boom"))
+	}
+
+	testCases := []struct {
+		Name        string
+		Middlewares []mux.MiddlewareFunc
+		Handler     mux.HandlerFunc
+		Code        int
+	}{
+		{
+			Name:    "Handler",
+			Handler: panicHandler,
+			Code:    http.StatusInternalServerError,
+		},
+		{
+			Name:        "Middleware",
+			Middlewares: []mux.MiddlewareFunc{panicMiddleware},
+			Handler:     okHandler,
+			Code:        http.StatusInternalServerError,
+		},
+		{
+			Name:    "NoPanic",
+			Handler: okHandler,
+			Code:    http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var handled error
+			errorFunc := func(err error, w http.ResponseWriter, r *http.Request) {
+				handled = err
+				mux.NegotiateErrorFunc(err, w, r)
+			}
+
+			router := mux.NewRouter(func(r *mux.Router) {
+				r.Wrapper = mux.NewDefaultWrapper(errorFunc, mux.WithPanicRecovery())
+			})
+			router.Use(tc.Middlewares...)
+			router.HandleFunc("/", tc.Handler)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+			if tc.Code == http.StatusOK {
+				return
+			}
+
+			var pe *mux.PanicError
+			if !errors.As(handled, &pe) {
+				t.Fatalf("got %v, expected panic error", handled)
+			}
+			if !strings.Contains(string(pe.Stack), "goroutine") {
+				t.Fatal("expected stack trace")
+			}
+		})
+	}
+}
+
+func TestWrapperRepanicOnAbort(t *testing.T) {
+	router := mux.NewRouter(func(r *mux.Router) {
+		r.Wrapper = mux.NewDefaultWrapper(errorHandler(500), mux.WithPanicRecovery(), mux.WithRepanicOnAbort())
+	})
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Fatalf("got %v, expected %v", v, http.ErrAbortHandler)
+		}
+	}()
+
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+}
+
+func TestScopedErrorHandlers(t *testing.T) {
+	denyMiddleware := func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		if r.Header.Get("X-Deny") != "" {
+			return nil, errors.New("denied")
+		}
+		return nil, nil
+	}
+
+	router := mux.NewRouter(mux.WithErrorHandler(errorHandler(http.StatusInternalServerError)))
+	router.Use(denyMiddleware)
+	router.HandleFunc("/", failedHandler)
+	router.Path("/route").WithErrorHandler(errorHandler(http.StatusConflict)).HandlerFunc(failedHandler)
+	router.HandleFunc("/late", failedHandler).WithErrorHandler(errorHandler(http.StatusGone))
+
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/users", failedHandler)
+
+	admin := router.PathPrefix("/admin").Subrouter(mux.WithErrorHandler(errorHandler(http.StatusTeapot)))
+	admin.HandleFunc("/users", failedHandler)
+	admin.HandleFunc("/login", failedHandler).WithErrorHandler(errorHandler(http.StatusUnauthorized))
+
+	testCases := []struct {
+		Name     string
+		Path     string
+		Deny     bool
+		Expected int
+	}{
+		{Name: "Root", Path: "/", Expected: http.StatusInternalServerError},
+		{Name: "Route", Path: "/route", Expected: http.StatusConflict},
+		{Name: "RouteAfterHandler", Path: "/late", Expected: http.StatusGone},
+		{Name: "InheritedSubrouter", Path: "/api/users", Expected: http.StatusInternalServerError},
+		{Name: "Subrouter", Path: "/admin/users", Expected: http.StatusTeapot},
+		{Name: "SubrouterRoute", Path: "/admin/login", Expected: http.StatusUnauthorized},
+		{Name: "RootMiddleware", Path: "/", Deny: true, Expected: http.StatusInternalServerError},
+		{Name: "RootMiddlewareInSubrouter", Path: "/admin/users", Deny: true, Expected: http.StatusTeapot},
+		{Name: "RootMiddlewareInRoute", Path: "/route", Deny: true, Expected: http.StatusConflict},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.Path, nil)
+			if tc.Deny {
+				r.Header.Set("X-Deny", "1")
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Expected {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Expected))
+			}
+		})
+	}
+}
+
+type headerWrapper struct {
+	mux.Wrapper
+}
+
+func (wr headerWrapper) ServeHandler(fn mux.HandlerFunc) func(w http.ResponseWriter, r *http.Request) {
+	h := wr.Wrapper.ServeHandler(fn)
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Wrapped", "true")
+		h(w, r)
+	}
+}
+
+func (wr headerWrapper) HandlerFunc(fn mux.HandlerFunc) http.HandlerFunc {
+	return wr.ServeHandler(fn)
+}
+
+func TestCustomWrapperErrorHandler(t *testing.T) {
+	withHeaderWrapper := func(r *mux.Router) {
+		r.Wrapper = headerWrapper{mux.NewDefaultWrapper(errorHandler(http.StatusInternalServerError))}
+	}
+
+	router := mux.NewRouter(withHeaderWrapper, mux.WithErrorHandler(errorHandler(http.StatusConflict)))
+	router.HandleFunc("/", failedHandler)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatal(newStatusError(resp.StatusCode, http.StatusConflict))
+	}
+	if resp.Header.Get("X-Wrapped") != "true" {
+		t.Fatal("expected custom wrapper to be kept")
+	}
+}
+
+func TestRouterGroup(t *testing.T) {
+	requireToken := func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		if r.Header.Get("Authorization") == "" {
+			return nil, errors.New("unauthorized")
+		}
+		return nil, nil
+	}
+
+	var usersGroup *mux.Router
+	router := mux.NewRouter(mux.WithErrorHandler(errorHandler(http.StatusUnauthorized)))
+	router.HandleFunc("/health", okHandler).Name("health")
+	router.Group("/v1", func(v1 *mux.Router) {
+		v1.NamePrefix("v1.")
+		v1.HandleFunc("/status", okHandler).Name("status")
+		v1.Group("/users", func(users *mux.Router) {
+			users.NamePrefix("users.")
+			usersGroup = users
+			users.HandleFunc("", okHandler).Name("list")
+			users.HandleFunc("/{id}", okHandler).Name("show")
+		}, requireToken)
+	})
+
+	testCases := []struct {
+		Name     string
+		Path     string
+		Token    bool
+		Expected int
+	}{
+		{Name: "Root", Path: "/health", Expected: http.StatusOK},
+		{Name: "Group", Path: "/v1/status", Expected: http.StatusOK},
+		{Name: "NestedGroup", Path: "/v1/users/1", Token: true, Expected: http.StatusOK},
+		{Name: "NestedGroupMiddleware", Path: "/v1/users", Expected: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tc.Path, nil)
+			if tc.Token {
+				r.Header.Set("Authorization", "Bearer token")
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			if w.Code != tc.Expected {
+				t.Fatal(newStatusError(w.Code, tc.Expected))
+			}
+		})
+	}
+
+	names := map[string]string{
+		"health":        "/health",
+		"v1.status":     "/v1/status",
+		"v1.users.list": "/v1/users",
+		"v1.users.show": "/v1/users/1",
+	}
+	for name, expected := range names {
+		route := router.Get(name)
+		if route.GetName() != name {
+			t.Fatalf("failed: route %q is not registered", name)
+		}
+		url, err := route.URL("id", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if url.String() != expected {
+			t.Fatalf("failed: got %s, expected %s", url, expected)
+		}
+	}
+
+	if name := usersGroup.Get("show").GetName(); name != "v1.users.show" {
+		t.Fatalf("failed: got route %q from group, expected %q", name, "v1.users.show")
+	}
+}
@@ -0,0 +1,165 @@
+package mux
+
+import (
+	"encoding/xml"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errorOffers = []string{
+	"application/json",
+	ProblemContentType,
+	"application/xml",
+	"text/plain",
+	"text/html",
+	"text/xml",
+}
+
+var errorTemplate = template.Must(template.New("error").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Code}} {{.Title}}</title>
+</head>
+<body>
+<h1>{{.Code}} {{.Title}}</h1>
+{{- if .Message}}
+<p>{{.Message}}</p>
+{{- end}}
+{{- with .Details}}
+<ul>
+{{- range .}}
+<li><strong>{{.Path}}</strong>: {{.Message}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .ErrorID}}
+<p>Error ID: <code>{{.ErrorID}}</code></p>
+{{- end}}
+</body>
+</html>
+`))
+
+// NegotiateErrorFunc writes error in format requested by `Accept` header:
+// JSON, problem details, XML, HTML or plain text. Status code is taken from
+// `HTTPError.Code`, other errors are reported as internal server error.
+func NegotiateErrorFunc(err error, w http.ResponseWriter, r *http.Request) {
+	e := toHTTPError(err)
+	code := e.status()
+	e.writeHeader(w)
+
+	contentType := negotiateContentType(r.Header.Get("Accept"), errorOffers)
+	switch contentType {
+	case ProblemContentType:
+		ProblemErrorFunc(e, w, r)
+	case "application/xml", "text/xml":
+		data, err := xml.Marshal(e)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(code)
+		w.Write([]byte(xml.Header))
+		w.Write(data)
+	case "text/html":
+		title := e.Title
+		if title == "" {
+			title = http.StatusText(code)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(code)
+		errorTemplate.Execute(w, map[string]interface{}{
+			"Code":    code,
+			"Title":   title,
+			"Message": e.Message,
+			"ErrorID": e.ErrorID,
+			"Details": e.Details,
+		})
+	case "text/plain":
+		http.Error(w, e.Message, code)
+	default:
+		writeJSON(w, code, "application/json", e)
+	}
+}
+
+type acceptSpec struct {
+	typ, subtype string
+	q            float64
+}
+
+// negotiateContentType returns the best offer for accept header value.
+// It returns the first offer if header is empty or nothing is acceptable.
+func negotiateContentType(accept string, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	specs := parseAccept(accept)
+	if len(specs) == 0 {
+		return offers[0]
+	}
+
+	best, bestQ := offers[0], 0.0
+	for _, offer := range offers {
+		typ, subtype := splitMediaType(offer)
+		q, specificity := 0.0, -1
+		for _, spec := range specs {
+			s := 0
+			switch {
+			case spec.typ == typ && spec.subtype == subtype:
+				s = 2
+			case spec.typ == typ && spec.subtype == "*":
+				s = 1
+			case spec.typ == "*" && spec.subtype == "*":
+				s = 0
+			default:
+				continue
+			}
+			if s > specificity {
+				q, specificity = spec.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
+
+func parseAccept(accept string) []acceptSpec {
+	var specs []acceptSpec
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype := splitMediaType(params[0])
+		if typ == "" {
+			continue
+		}
+		spec := acceptSpec{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && strings.ToLower(kv[0]) == "q" {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					spec.q = q
+				}
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+func splitMediaType(mediaType string) (string, string) {
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	i := strings.IndexByte(mediaType, '/')
+	if i < 0 {
+		if mediaType == "*" {
+			return "*", "*"
+		}
+		return "", ""
+	}
+	return mediaType[:i], mediaType[i+1:]
+}
@@ -0,0 +1,189 @@
+package mux_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func notFoundHandler(w http.ResponseWriter, r *http.Request) error {
+	return mux.NewHTTPError(http.StatusNotFound, "User not found")
+}
+
+func TestNegotiateErrorFunc(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Accept      string
+		Handler     mux.HandlerFunc
+		Code        int
+		ContentType string
+		Expected    string
+	}{
+		{
+			Name:        "Empty",
+			Handler:     notFoundHandler,
+			Code:        http.StatusNotFound,
+			ContentType: "application/json",
+			Expected:    `{"code":404,"message":"User not found"}`,
+		},
+		{
+			Name:        "JSON",
+			Accept:      "application/json",
+			Handler:     failedHandler,
+			Code:        http.StatusInternalServerError,
+			ContentType: "application/json",
+			Expected:    `{"code":500,"message":"Internal Server Error"}`,
+		},
+		{
+			Name:        "Problem",
+			Accept:      "application/problem+json",
+			Handler:     notFoundHandler,
+			Code:        http.StatusNotFound,
+			ContentType: "application/problem+json",
+			Expected:    `{"title":"Not Found","status":404,"detail":"User not found"}`,
+		},
+		{
+			Name:        "XML",
+			Accept:      "application/xml",
+			Handler:     notFoundHandler,
+			Code:        http.StatusNotFound,
+			ContentType: "application/xml; charset=utf-8",
+			Expected:    `<error><code>404</code><message>User not found</message></error>`,
+		},
+		{
+			Name:        "HTML",
+			Accept:      "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			Handler:     notFoundHandler,
+			Code:        http.StatusNotFound,
+			ContentType: "text/html; charset=utf-8",
+			Expected:    "<p>User not found</p>",
+		},
+		{
+			Name:        "Text",
+			Accept:      "text/*",
+			Handler:     notFoundHandler,
+			Code:        http.StatusNotFound,
+			ContentType: "text/plain; charset=utf-8",
+			Expected:    "User not found",
+		},
+		{
+			Name:        "Unacceptable",
+			Accept:      "image/png",
+			Handler:     notFoundHandler,
+			Code:        http.StatusNotFound,
+			ContentType: "application/json",
+			Expected:    `{"code":404,"message":"User not found"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mux := mux.NewRouter(func(r *mux.Router) { r.Wrapper = mux.NewDefaultWrapper(mux.NegotiateErrorFunc) })
+			mux.HandleFunc("/", tc.Handler)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if tc.Accept != "" {
+				r.Header.Set("Accept", tc.Accept)
+			}
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != tc.ContentType {
+				t.Fatalf("got content type %s, expected %s", ct, tc.ContentType)
+			}
+
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if !strings.Contains(string(data), tc.Expected) {
+				t.Fatalf("failed: got %s, expected %s", string(data), tc.Expected)
+			}
+		})
+	}
+}
+
+func TestNegotiateErrorFuncSentinel(t *testing.T) {
+	router := mux.NewRouter(func(r *mux.Router) { r.Wrapper = mux.NewDefaultWrapper(mux.NegotiateErrorFunc) })
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("find user: %w", mux.ErrNotFound)
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatal(newStatusError(resp.StatusCode, http.StatusNotFound))
+	}
+}
+
+func TestErrorFuncHeaders(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		ErrorFunc mux.ErrorHandlerFunc
+		Err       error
+		Header    string
+		Expected  []string
+	}{
+		{
+			Name:      "Basic",
+			ErrorFunc: nil,
+			Err:       mux.NewHTTPError(http.StatusServiceUnavailable, "Unavailable").WithHeader("Retry-After", "120"),
+			Header:    "Retry-After",
+			Expected:  []string{"120"},
+		},
+		{
+			Name:      "Negotiate",
+			ErrorFunc: mux.NegotiateErrorFunc,
+			Err: mux.NewHTTPError(http.StatusUnauthorized, "Unauthorized").
+				WithHeader("WWW-Authenticate", `Bearer realm="api"`).
+				WithHeader("WWW-Authenticate", `Basic realm="api"`),
+			Header:   "WWW-Authenticate",
+			Expected: []string{`Bearer realm="api"`, `Basic realm="api"`},
+		},
+		{
+			Name:      "Problem",
+			ErrorFunc: mux.ProblemErrorFunc,
+			Err:       fmt.Errorf("wrapped: %w", mux.NewHTTPError(http.StatusMethodNotAllowed, "Not Allowed").WithHeader("Allow", "GET, POST")),
+			Header:    "Allow",
+			Expected:  []string{"GET, POST"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter(func(r *mux.Router) {
+				if tc.ErrorFunc != nil {
+					r.Wrapper = mux.NewDefaultWrapper(tc.ErrorFunc)
+				}
+			})
+			router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error { return tc.Err })
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if got := resp.Header.Values(tc.Header); !reflect.DeepEqual(got, tc.Expected) {
+				t.Fatalf("got %s %v, expected %v", tc.Header, got, tc.Expected)
+			}
+		})
+	}
+}
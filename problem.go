@@ -0,0 +1,145 @@
+package mux
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// ProblemContentType is the media type of RFC 7807 problem details.
+const ProblemContentType = "application/problem+json"
+
+type problem struct {
+	Type     string `json:"type,omitempty"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
+var reservedMembers = map[string]bool{
+	"type":            true,
+	"title":           true,
+	"status":          true,
+	"detail":          true,
+	"instance":        true,
+	"id":              true,
+	"internalMessage": true,
+	"internalError":   true,
+	"details":         true,
+}
+
+// ProblemErrorFunc writes error as `application/problem+json` body.
+// Errors other than `HTTPError` are reported as internal server error.
+func ProblemErrorFunc(err error, w http.ResponseWriter, r *http.Request) {
+	e := *toHTTPError(err)
+	e.ProblemDetails = true
+	e.writeHeader(w)
+	writeJSON(w, e.status(), ProblemContentType, &e)
+}
+
+func (e *HTTPError) marshalProblem() ([]byte, error) {
+	title := e.Title
+	if title == "" {
+		title = http.StatusText(e.Code)
+	}
+	data, err := json.Marshal(&problem{
+		Type:     e.Type,
+		Title:    title,
+		Status:   e.Code,
+		Detail:   e.Message,
+		Instance: e.Instance,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]interface{})
+	for k, v := range e.Extensions {
+		if !reservedMembers[k] {
+			members[k] = v
+		}
+	}
+	if e.ErrorID != "" {
+		members["id"] = e.ErrorID
+	}
+	if e.InternalMessage != "" {
+		members["internalMessage"] = e.InternalMessage
+	}
+	if e.ShowError && e.InternalError != nil {
+		members["internalError"] = e.InternalError.Error()
+	}
+	if len(e.Details) > 0 {
+		members["details"] = e.Details
+	}
+	if len(members) == 0 {
+		return data, nil
+	}
+
+	ext, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data[:len(data)-1], ',')
+	return append(data, ext[1:]...), nil
+}
+
+func (e *HTTPError) unmarshalProblem(b []byte) error {
+	var data problem
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+	e.ProblemDetails = true
+	e.Code = data.Status
+	e.Message = data.Detail
+	e.Type = data.Type
+	e.Title = data.Title
+	e.Instance = data.Instance
+	for k, raw := range members {
+		var err error
+		switch k {
+		case "id":
+			err = json.Unmarshal(raw, &e.ErrorID)
+		case "internalMessage":
+			err = json.Unmarshal(raw, &e.InternalMessage)
+		case "details":
+			err = json.Unmarshal(raw, &e.Details)
+		case "internalError":
+			var message string
+			err = json.Unmarshal(raw, &message)
+			if message != "" {
+				e.ShowError = true
+				e.InternalError = errors.New(message)
+			}
+		default:
+			if reservedMembers[k] {
+				continue
+			}
+			var v interface{}
+			err = json.Unmarshal(raw, &v)
+			e.WithExtension(k, v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isProblem reports whether b holds problem details rather than
+// `HTTPError` own format. Problem details are recognized by `status` with
+// `title` or `type`, so `code` is free to be used as extension member.
+func isProblem(b []byte) bool {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(b, &members); err != nil {
+		return false
+	}
+	_, hasStatus := members["status"]
+	_, hasTitle := members["title"]
+	_, hasType := members["type"]
+	return hasStatus && (hasTitle || hasType)
+}
@@ -0,0 +1,191 @@
+package mux_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func ExampleHTTPError_problemDetails() {
+	err := mux.NewHTTPError(http.StatusForbidden, "Your current balance is 30, but that costs 50.").
+		WithProblemDetails(true).
+		WithType("https://example.com/probs/out-of-credit").
+		WithTitle("You do not have enough credit.").
+		WithInstance("/account/12345/msgs/abc").
+		WithExtension("balance", 30).
+		WithErrorID("123")
+	data, _ := json.Marshal(err)
+	fmt.Println(string(data))
+
+	err = mux.NewHTTPError(http.StatusNotFound, "").WithProblemDetails(true)
+	data, _ = json.Marshal(err)
+	fmt.Println(string(data))
+
+	// Output:
+	// {"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":403,"detail":"Your current balance is 30, but that costs 50.","instance":"/account/12345/msgs/abc","balance":30,"id":"123"}
+	// {"title":"Not Found","status":404}
+}
+
+func TestHTTPErrorUnmarshal(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Data     string
+		Problem  bool
+		Code     int
+		Message  string
+		ErrorID  string
+		Internal string
+	}{
+		{
+			Name:     "Default",
+			Data:     `{"code":500,"message":"Server Error","internalError":"Unexpected Error","id":"123"}`,
+			Code:     http.StatusInternalServerError,
+			Message:  "Server Error",
+			ErrorID:  "123",
+			Internal: "Unexpected Error",
+		},
+		{
+			Name:     "Problem",
+			Data:     `{"type":"about:blank","title":"Conflict","status":409,"detail":"Already exists","internalError":"duplicate key","id":"456","field":"email"}`,
+			Problem:  true,
+			Code:     http.StatusConflict,
+			Message:  "Already exists",
+			ErrorID:  "456",
+			Internal: "duplicate key",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var e mux.HTTPError
+			if err := json.Unmarshal([]byte(tc.Data), &e); err != nil {
+				t.Fatal(err)
+			}
+			if e.ProblemDetails != tc.Problem {
+				t.Fatalf("got problem details %v, expected %v", e.ProblemDetails, tc.Problem)
+			}
+			if e.Code != tc.Code {
+				t.Fatal(newStatusError(e.Code, tc.Code))
+			}
+			if e.Message != tc.Message {
+				t.Fatalf("got message %q, expected %q", e.Message, tc.Message)
+			}
+			if e.ErrorID != tc.ErrorID {
+				t.Fatalf("got id %q, expected %q", e.ErrorID, tc.ErrorID)
+			}
+			if e.InternalError == nil || e.InternalError.Error() != tc.Internal {
+				t.Fatalf("got internal error %v, expected %q", e.InternalError, tc.Internal)
+			}
+			if tc.Problem && e.Extensions["field"] != "email" {
+				t.Fatalf("got extensions %v, expected field member", e.Extensions)
+			}
+		})
+	}
+}
+
+func TestProblemCodeExtension(t *testing.T) {
+	testCases := []struct {
+		Name string
+		Code interface{}
+	}{
+		{Name: "String", Code: "INVALID_EMAIL"},
+		{Name: "Number", Code: 1234},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := mux.NewHTTPError(http.StatusBadRequest, "bad").
+				WithProblemDetails(true).
+				WithTitle("Invalid email").
+				WithExtension("code", tc.Code)
+			data, jerr := json.Marshal(err)
+			if jerr != nil {
+				t.Fatal(jerr)
+			}
+
+			var e mux.HTTPError
+			if err := json.Unmarshal(data, &e); err != nil {
+				t.Fatal(err)
+			}
+			if !e.ProblemDetails {
+				t.Fatalf("expected problem details from %s", data)
+			}
+			if e.Code != http.StatusBadRequest {
+				t.Fatal(newStatusError(e.Code, http.StatusBadRequest))
+			}
+			if e.Title != "Invalid email" || e.Message != "bad" {
+				t.Fatalf("got title %q and message %q", e.Title, e.Message)
+			}
+			if fmt.Sprint(e.Extensions["code"]) != fmt.Sprint(tc.Code) {
+				t.Fatalf("got code extension %v, expected %v", e.Extensions["code"], tc.Code)
+			}
+		})
+	}
+}
+
+func TestProblemErrorFunc(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Handler mux.HandlerFunc
+		Code    int
+		Detail  string
+	}{
+		{
+			Name: "HTTPError",
+			Handler: func(w http.ResponseWriter, r *http.Request) error {
+				return mux.NewHTTPError(http.StatusBadRequest, "Invalid payload")
+			},
+			Code:   http.StatusBadRequest,
+			Detail: "Invalid payload",
+		},
+		{
+			Name:    "Error",
+			Handler: failedHandler,
+			Code:    http.StatusInternalServerError,
+			Detail:  http.StatusText(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			mux := mux.NewRouter(func(r *mux.Router) { r.Wrapper = mux.NewDefaultWrapper(mux.ProblemErrorFunc) })
+			mux.HandleFunc("/", tc.Handler)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			mux.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+				t.Fatalf("got content type %s", ct)
+			}
+
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var problem map[string]interface{}
+			if err := json.Unmarshal(data, &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem["status"] != float64(tc.Code) || problem["detail"] != tc.Detail {
+				t.Fatal(errors.New("failed: " + string(data)))
+			}
+			if _, ok := problem["internalError"]; ok {
+				t.Fatal(errors.New("internal error must be hidden: " + string(data)))
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package mux
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// ErrorRegistry maps domain errors to `HTTPError`.
+type ErrorRegistry struct {
+	mu       sync.RWMutex
+	mappings []errorMapping
+}
+
+type errorMapping struct {
+	target  error
+	typ     reflect.Type
+	code    int
+	message string
+}
+
+// NewErrorRegistry returns a new empty registry.
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{}
+}
+
+// Register maps errors matching target by `errors.Is` to status code and
+// message. Empty message is replaced by status text.
+func (reg *ErrorRegistry) Register(target error, code int, message string) *ErrorRegistry {
+	return reg.add(errorMapping{target: target, code: code, message: message})
+}
+
+// RegisterType maps errors matching type of target by `errors.As` to status
+// code and message. Target is a value of error type, usually nil pointer,
+// e.g. `(*os.PathError)(nil)`.
+func (reg *ErrorRegistry) RegisterType(target error, code int, message string) *ErrorRegistry {
+	if target == nil {
+		panic("mux: nil target type")
+	}
+	return reg.add(errorMapping{typ: reflect.TypeOf(target), code: code, message: message})
+}
+
+func (reg *ErrorRegistry) add(m errorMapping) *ErrorRegistry {
+	if m.message == "" {
+		m.message = http.StatusText(m.code)
+	}
+	reg.mu.Lock()
+	reg.mappings = append(reg.mappings, m)
+	reg.mu.Unlock()
+	return reg
+}
+
+// Resolve returns `HTTPError` for err. `HTTPError` found in err chain is
+// returned as is, registered errors are matched in order of registration.
+// Unmatched errors are resolved to internal server error with err hidden
+// in `InternalError`.
+func (reg *ErrorRegistry) Resolve(err error) *HTTPError {
+	var e *HTTPError
+	if errors.As(err, &e) {
+		return e
+	}
+	reg.mu.RLock()
+	defer reg.mu.RUnlock()
+	for _, m := range reg.mappings {
+		if m.match(err) {
+			return NewHTTPError(m.code, m.message).WithInternalError(err)
+		}
+	}
+	return toHTTPError(err)
+}
+
+func (m *errorMapping) match(err error) bool {
+	if m.typ == nil {
+		return errors.Is(err, m.target)
+	}
+	return errors.As(err, reflect.New(m.typ).Interface())
+}
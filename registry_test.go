@@ -0,0 +1,131 @@
+package mux_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+var errNoRows = errors.New("sql: no rows in result set")
+
+type quotaError struct{ Limit int }
+
+func (e *quotaError) Error() string { return fmt.Sprintf("quota %d exceeded", e.Limit) }
+
+func TestErrorRegistry(t *testing.T) {
+	registry := mux.NewErrorRegistry().
+		Register(errNoRows, http.StatusNotFound, "Resource not found").
+		RegisterType((*quotaError)(nil), http.StatusTooManyRequests, "")
+
+	testCases := []struct {
+		Name     string
+		Err      error
+		Code     int
+		Expected string
+	}{
+		{
+			Name:     "Is",
+			Err:      fmt.Errorf("load user: %w", errNoRows),
+			Code:     http.StatusNotFound,
+			Expected: "Resource not found",
+		},
+		{
+			Name:     "As",
+			Err:      fmt.Errorf("upload: %w", &quotaError{Limit: 10}),
+			Code:     http.StatusTooManyRequests,
+			Expected: http.StatusText(http.StatusTooManyRequests),
+		},
+		{
+			Name:     "HTTPError",
+			Err:      mux.NewHTTPError(http.StatusConflict, "Already exists").WithInternalError(errNoRows),
+			Code:     http.StatusConflict,
+			Expected: "Already exists",
+		},
+		{
+			Name:     "Sentinel",
+			Err:      mux.ErrForbidden,
+			Code:     http.StatusForbidden,
+			Expected: http.StatusText(http.StatusForbidden),
+		},
+		{
+			Name:     "Unmatched",
+			Err:      errors.New("connection refused"),
+			Code:     http.StatusInternalServerError,
+			Expected: http.StatusText(http.StatusInternalServerError),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			e := registry.Resolve(tc.Err)
+			if e.Code != tc.Code {
+				t.Fatal(newStatusError(e.Code, tc.Code))
+			}
+			if e.Message != tc.Expected {
+				t.Fatalf("got message %q, expected %q", e.Message, tc.Expected)
+			}
+			if !errors.Is(e, tc.Err) {
+				t.Fatalf("resolved error must wrap %v", tc.Err)
+			}
+		})
+	}
+}
+
+func TestWrapperErrorRegistry(t *testing.T) {
+	registry := mux.NewErrorRegistry().Register(errNoRows, http.StatusNotFound, "")
+
+	testCases := []struct {
+		Name     string
+		Err      error
+		Code     int
+		Expected string
+	}{
+		{
+			Name:     "Matched",
+			Err:      errNoRows,
+			Code:     http.StatusNotFound,
+			Expected: `{"code":404,"message":"Not Found"}`,
+		},
+		{
+			Name:     "Hidden",
+			Err:      errors.New("dial tcp: connection refused"),
+			Code:     http.StatusInternalServerError,
+			Expected: `{"code":500,"message":"Internal Server Error"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter(func(r *mux.Router) {
+				r.Wrapper = mux.NewDefaultWrapper(mux.NegotiateErrorFunc, mux.WithErrorRegistry(registry))
+			})
+			router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error { return tc.Err })
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+
+			data, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if strings.TrimSpace(string(data)) != tc.Expected {
+				t.Fatalf("failed: got %s, expected %s", string(data), tc.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package mux
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to pass request ID.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+// NewRequestID returns a new random request ID.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestIDMiddleware takes request ID from `X-Request-ID` header or
+// creates a new one, sets it on response and stores it in context.
+// See RequestID().
+//
+// Router middlewares run only for matched routes, so responses of
+// `NotFoundHandler` and `MethodNotAllowedHandler` carry no request ID unless
+// wrapper uses `WithErrorIDs`.
+func RequestIDMiddleware(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+	id := r.Header.Get(RequestIDHeader)
+	if !validRequestID(id) {
+		id = NewRequestID()
+	}
+	w.Header().Set(RequestIDHeader, id)
+	return context.WithValue(r.Context(), requestIDContextKey, id), nil
+}
+
+// RequestID returns request ID stored by `RequestIDMiddleware`, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey).(string)
+	return id
+}
+
+// validRequestID reports whether incoming id is safe to be reused.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,261 @@
+package mux_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func TestWrapperErrorIDs(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		RequestID string
+		Handler   mux.HandlerFunc
+		Expected  string
+	}{
+		{
+			Name:      "Incoming",
+			RequestID: "abc-123",
+			Handler:   notFoundHandler,
+			Expected:  "abc-123",
+		},
+		{
+			Name:     "Generated",
+			Handler:  notFoundHandler,
+			Expected: "generated",
+		},
+		{
+			Name:      "Invalid",
+			RequestID: "bad id",
+			Handler:   failedHandler,
+			Expected:  "generated",
+		},
+		{
+			Name:      "Existing",
+			RequestID: "abc-123",
+			Handler: func(w http.ResponseWriter, r *http.Request) error {
+				return mux.NewHTTPError(http.StatusConflict, "Conflict").WithErrorID("own")
+			},
+			Expected: "own",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var handled error
+			errorFunc := func(err error, w http.ResponseWriter, r *http.Request) {
+				handled = err
+				mux.NegotiateErrorFunc(err, w, r)
+			}
+			generate := func() string { return "generated" }
+
+			router := mux.NewRouter(func(r *mux.Router) {
+				r.Wrapper = mux.NewDefaultWrapper(errorFunc, mux.WithErrorIDs(generate))
+			})
+			router.HandleFunc("/", tc.Handler)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if tc.RequestID != "" {
+				r.Header.Set("X-Request-ID", tc.RequestID)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if id := resp.Header.Get("X-Request-ID"); id != tc.Expected {
+				t.Fatalf("got header %q, expected %q", id, tc.Expected)
+			}
+
+			var e *mux.HTTPError
+			if !errors.As(handled, &e) || e.ErrorID != tc.Expected {
+				t.Fatalf("got error %#v, expected id %q", handled, tc.Expected)
+			}
+
+			var body mux.HTTPError
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if body.ErrorID != tc.Expected {
+				t.Fatalf("got body id %q, expected %q", body.ErrorID, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	a, b := mux.NewRequestID(), mux.NewRequestID()
+	if len(a) != 32 || a == b {
+		t.Fatalf("got %q and %q", a, b)
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		RequestID string
+		Handler   mux.HandlerFunc
+	}{
+		{Name: "Incoming", RequestID: "abc-123", Handler: okHandler},
+		{Name: "Generated", Handler: okHandler},
+		{Name: "Invalid", RequestID: "bad id", Handler: okHandler},
+		{Name: "Error", RequestID: "abc-123", Handler: notFoundHandler},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var seen string
+			router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+			router.Use(mux.RequestIDMiddleware)
+			router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+				seen = mux.RequestID(r.Context())
+				return tc.Handler(w, r)
+			})
+
+			r := httptest.NewRequest("GET", "/", nil)
+			if tc.RequestID != "" {
+				r.Header.Set("X-Request-ID", tc.RequestID)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			id := resp.Header.Get("X-Request-ID")
+			if id != seen {
+				t.Fatalf("got header %q, expected %q", id, seen)
+			}
+			if tc.RequestID == "abc-123" && id != tc.RequestID {
+				t.Fatalf("got header %q, expected %q", id, tc.RequestID)
+			}
+			if tc.RequestID != "abc-123" && len(id) != 32 {
+				t.Fatalf("got header %q, expected generated id", id)
+			}
+
+			if resp.StatusCode == http.StatusOK {
+				return
+			}
+			var body mux.HTTPError
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if body.ErrorID != id {
+				t.Fatalf("got body id %q, expected %q", body.ErrorID, id)
+			}
+		})
+	}
+}
+
+func TestRequestIDEmpty(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	if id := mux.RequestID(r.Context()); id != "" {
+		t.Fatalf("got %q, expected empty id", id)
+	}
+}
+
+var errSharedNotFound = mux.NewHTTPError(http.StatusNotFound, "Not found")
+
+func TestWrapperErrorIDsSharedError(t *testing.T) {
+	router := mux.NewRouter(func(r *mux.Router) {
+		r.Wrapper = mux.NewDefaultWrapper(mux.NegotiateErrorFunc, mux.WithErrorIDs(nil))
+	})
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		return errSharedNotFound
+	})
+
+	for _, id := range []string{"first", "second"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Request-ID", id)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, r)
+		resp := w.Result()
+
+		if got := resp.Header.Get("X-Request-ID"); got != id {
+			t.Fatalf("got header %q, expected %q", got, id)
+		}
+		var body mux.HTTPError
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if body.ErrorID != id {
+			t.Fatalf("got body id %q, expected %q", body.ErrorID, id)
+		}
+	}
+
+	if errSharedNotFound.ErrorID != "" {
+		t.Fatalf("shared error was modified: id %q", errSharedNotFound.ErrorID)
+	}
+}
+
+func TestRequestIDMiddlewareSharedError(t *testing.T) {
+	router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+	router.Use(mux.RequestIDMiddleware)
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		return errSharedNotFound
+	})
+
+	for _, id := range []string{"first", "second"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("X-Request-ID", id)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, r)
+
+		var body mux.HTTPError
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.ErrorID != id {
+			t.Fatalf("got body id %q, expected %q", body.ErrorID, id)
+		}
+	}
+
+	if errSharedNotFound.ErrorID != "" {
+		t.Fatalf("shared error was modified: id %q", errSharedNotFound.ErrorID)
+	}
+}
+
+type domainError struct{ reason string }
+
+func (e *domainError) Error() string { return e.reason }
+
+func TestRequestIDMiddlewareKeepsErrorType(t *testing.T) {
+	var handled error
+	errorFunc := func(err error, w http.ResponseWriter, r *http.Request) {
+		handled = err
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	router := mux.NewRouter(mux.WithErrorHandler(errorFunc))
+	router.Use(mux.RequestIDMiddleware)
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		return &domainError{reason: "stock is empty"}
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-Request-ID", "abc")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+
+	if _, ok := handled.(*domainError); !ok {
+		t.Fatalf("got %T, expected *domainError", handled)
+	}
+	if body := strings.TrimSpace(w.Body.String()); body != "stock is empty" {
+		t.Fatalf("got body %q", body)
+	}
+	if id := w.Header().Get("X-Request-ID"); id != "abc" {
+		t.Fatalf("got header id %q, expected %q", id, "abc")
+	}
+}
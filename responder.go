@@ -0,0 +1,174 @@
+package mux
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Responder describes response returned by `ResponderFunc`.
+type Responder interface {
+	Status() int
+	Header() http.Header
+	Encode(w io.Writer) error
+}
+
+// ResponderFunc returns description of response instead of writing it.
+type ResponderFunc func(r *http.Request) (Responder, error)
+
+// preparer is implemented by responders that can fail before anything is
+// written, so error still can be handled by `Wrapper`.
+type preparer interface {
+	prepare() error
+}
+
+// Respond adapts fn into `HandlerFunc`. Nil responder is written as
+// `NoContent`.
+func Respond(fn ResponderFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		res, err := fn(r)
+		if err != nil {
+			return err
+		}
+		if res == nil {
+			res = NoContent()
+		}
+		if p, ok := res.(preparer); ok {
+			if err := p.prepare(); err != nil {
+				return err
+			}
+		}
+		for k, vv := range res.Header() {
+			w.Header()[k] = vv
+		}
+		w.WriteHeader(res.Status())
+		return res.Encode(w)
+	}
+}
+
+// HandleResponder registers a new route with a matcher for the URL path.
+// See Route.Path() and Route.ResponderFunc().
+func (r *Router) HandleResponder(path string, fn ResponderFunc) *Route {
+	return r.NewRoute().Path(path).ResponderFunc(fn)
+}
+
+// ResponderFunc sets a responder function for the route.
+func (r *Route) ResponderFunc(fn ResponderFunc) *Route {
+	return r.HandlerFunc(Respond(fn))
+}
+
+type response struct {
+	status int
+	header http.Header
+}
+
+func newResponse(code int) response {
+	return response{status: code, header: make(http.Header)}
+}
+
+func (res *response) Status() int         { return res.status }
+func (res *response) Header() http.Header { return res.header }
+
+type jsonResponse struct {
+	response
+	value interface{}
+	data  []byte
+}
+
+// JSONResponse returns `Responder` writing v as JSON with status code.
+func JSONResponse(code int, v interface{}) Responder {
+	res := &jsonResponse{response: newResponse(code), value: v}
+	res.header.Set("Content-Type", "application/json")
+	return res
+}
+
+func (res *jsonResponse) prepare() error {
+	data, err := json.Marshal(res.value)
+	if err != nil {
+		return err
+	}
+	res.data = data
+	return nil
+}
+
+func (res *jsonResponse) Encode(w io.Writer) error {
+	if res.data == nil {
+		if err := res.prepare(); err != nil {
+			return err
+		}
+	}
+	_, err := w.Write(res.data)
+	return err
+}
+
+type emptyResponse struct{ response }
+
+func (res *emptyResponse) Encode(w io.Writer) error { return nil }
+
+// NoContent returns `Responder` with status 204 and empty body.
+func NoContent() Responder {
+	return &emptyResponse{newResponse(http.StatusNoContent)}
+}
+
+// Redirect returns `Responder` redirecting to url with status code.
+func Redirect(url string, code int) Responder {
+	res := &emptyResponse{newResponse(code)}
+	res.header.Set("Location", url)
+	return res
+}
+
+type streamResponse struct {
+	response
+	reader io.Reader
+}
+
+// Stream returns `Responder` copying rd to response body with status 200.
+// If rd is `io.Closer`, it is closed after copying.
+func Stream(contentType string, rd io.Reader) Responder {
+	res := &streamResponse{response: newResponse(http.StatusOK), reader: rd}
+	res.header.Set("Content-Type", contentType)
+	return res
+}
+
+func (res *streamResponse) Encode(w io.Writer) error {
+	if c, ok := res.reader.(io.Closer); ok {
+		defer c.Close()
+	}
+	_, err := io.Copy(w, res.reader)
+	return err
+}
+
+// File returns `Responder` writing named file with content type detected by
+// extension. Missing file is reported as `HTTPError` with status 404.
+func File(name string) (Responder, error) {
+	f, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil, NewHTTPError(http.StatusNotFound, http.StatusText(http.StatusNotFound)).WithInternalError(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		f.Close()
+		return nil, NewHTTPError(http.StatusNotFound, http.StatusText(http.StatusNotFound))
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	res := &streamResponse{response: newResponse(http.StatusOK), reader: f}
+	res.header.Set("Content-Type", contentType)
+	res.header.Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	res.header.Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+	return res, nil
+}
@@ -0,0 +1,197 @@
+package mux_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func TestResponderFunc(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "report.txt")
+	if err := ioutil.WriteFile(file, []byte("report"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name        string
+		Responder   mux.ResponderFunc
+		Code        int
+		Header      string
+		HeaderValue string
+		Expected    string
+	}{
+		{
+			Name: "JSON",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.JSONResponse(http.StatusCreated, map[string]int{"id": 1}), nil
+			},
+			Code:        http.StatusCreated,
+			Header:      "Content-Type",
+			HeaderValue: "application/json",
+			Expected:    `{"id":1}`,
+		},
+		{
+			Name: "NoContent",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.NoContent(), nil
+			},
+			Code: http.StatusNoContent,
+		},
+		{
+			Name: "Nil",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return nil, nil
+			},
+			Code: http.StatusNoContent,
+		},
+		{
+			Name: "Redirect",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.Redirect("/login", http.StatusFound), nil
+			},
+			Code:        http.StatusFound,
+			Header:      "Location",
+			HeaderValue: "/login",
+		},
+		{
+			Name: "File",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.File(file)
+			},
+			Code:        http.StatusOK,
+			Header:      "Content-Type",
+			HeaderValue: "text/plain; charset=utf-8",
+			Expected:    "report",
+		},
+		{
+			Name: "MissingFile",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.File(filepath.Join(dir, "missing.txt"))
+			},
+			Code:     http.StatusNotFound,
+			Expected: `{"code":404,"message":"Not Found"}`,
+		},
+		{
+			Name: "Stream",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.Stream("text/csv", strings.NewReader("a,b\n1,2\n")), nil
+			},
+			Code:        http.StatusOK,
+			Header:      "Content-Type",
+			HeaderValue: "text/csv",
+			Expected:    "a,b\n1,2",
+		},
+		{
+			Name: "EncodeError",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.JSONResponse(http.StatusOK, func() {}), nil
+			},
+			Code:     http.StatusInternalServerError,
+			Expected: `{"code":500,"message":"Internal Server Error"}`,
+		},
+		{
+			Name: "Error",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return nil, mux.ErrForbidden
+			},
+			Code:     http.StatusForbidden,
+			Expected: `{"code":403,"message":"Forbidden"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+			router.HandleResponder("/", tc.Responder)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+			if tc.Header != "" && resp.Header.Get(tc.Header) != tc.HeaderValue {
+				t.Fatalf("got %s %q, expected %q", tc.Header, resp.Header.Get(tc.Header), tc.HeaderValue)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tc.Expected {
+				t.Fatalf("failed: got %s, expected %s", body, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestRespond(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Responder mux.ResponderFunc
+		Err       bool
+		Code      int
+		Expected  string
+	}{
+		{
+			Name: "JSON",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.JSONResponse(http.StatusCreated, map[string]int{"id": 1}), nil
+			},
+			Code:     http.StatusCreated,
+			Expected: `{"id":1}`,
+		},
+		{
+			Name: "Nil",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return nil, nil
+			},
+			Code: http.StatusNoContent,
+		},
+		{
+			Name: "Error",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return nil, mux.ErrNotFound
+			},
+			Err: true,
+		},
+		{
+			Name: "EncodeError",
+			Responder: func(r *http.Request) (mux.Responder, error) {
+				return mux.JSONResponse(http.StatusOK, make(chan int)), nil
+			},
+			Err: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			err := mux.Respond(tc.Responder)(w, r)
+			if tc.Err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				if w.Code != http.StatusOK || w.Body.Len() != 0 || len(w.Header()) != 0 {
+					t.Fatal("expected nothing written on error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != tc.Code {
+				t.Fatal(newStatusError(w.Code, tc.Code))
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tc.Expected {
+				t.Fatalf("failed: got %s, expected %s", body, tc.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package mux
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter is passed to handlers and middlewares served by default
+// `Wrapper`. It tracks status code and size of response. It implements
+// `http.Flusher`, `http.Hijacker` and `io.ReaderFrom` only if underlying
+// writer does, `http.ResponseController` reaches the rest through `Unwrap`.
+type ResponseWriter interface {
+	http.ResponseWriter
+	// Status returns written status code or 0 if nothing is written yet.
+	Status() int
+	// Size returns number of written body bytes.
+	Size() int64
+	// Committed reports whether headers are already sent.
+	Committed() bool
+	// Unwrap returns underlying writer, used by `http.ResponseController`.
+	Unwrap() http.ResponseWriter
+}
+
+// Committed reports whether response headers of w are already sent.
+// It is always false for writers not created by `Wrapper`.
+func Committed(w http.ResponseWriter) bool {
+	rw, ok := w.(interface{ Committed() bool })
+	return ok && rw.Committed()
+}
+
+type responseWriter struct {
+	w       http.ResponseWriter
+	status  int
+	size    int64
+	discard bool
+}
+
+// trackedWriter is implemented by writers returned by `responseWriter.writer`.
+type trackedWriter interface {
+	tracked() *responseWriter
+}
+
+// wrapResponseWriter returns tracking writer of w if it is already tracked.
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	if tw, ok := w.(trackedWriter); ok {
+		return tw.tracked()
+	}
+	return &responseWriter{w: w}
+}
+
+// writer returns rw with optional interfaces supported by underlying writer,
+// so type assertions on it answer the same as on underlying writer.
+func (rw *responseWriter) writer() http.ResponseWriter {
+	_, rf := rw.w.(io.ReaderFrom)
+	_, f := rw.w.(http.Flusher)
+	_, hj := rw.w.(http.Hijacker)
+	switch {
+	case rf && f && hj:
+		return struct {
+			*responseWriter
+			readerFrom
+			flusher
+			hijacker
+		}{rw, readerFrom{rw}, flusher{rw}, hijacker{rw}}
+	case rf && f:
+		return struct {
+			*responseWriter
+			readerFrom
+			flusher
+		}{rw, readerFrom{rw}, flusher{rw}}
+	case rf && hj:
+		return struct {
+			*responseWriter
+			readerFrom
+			hijacker
+		}{rw, readerFrom{rw}, hijacker{rw}}
+	case f && hj:
+		return struct {
+			*responseWriter
+			flusher
+			hijacker
+		}{rw, flusher{rw}, hijacker{rw}}
+	case rf:
+		return struct {
+			*responseWriter
+			readerFrom
+		}{rw, readerFrom{rw}}
+	case f:
+		return struct {
+			*responseWriter
+			flusher
+		}{rw, flusher{rw}}
+	case hj:
+		return struct {
+			*responseWriter
+			hijacker
+		}{rw, hijacker{rw}}
+	}
+	return rw
+}
+
+type readerFrom struct{ rw *responseWriter }
+
+func (w readerFrom) ReadFrom(src io.Reader) (int64, error) { return w.rw.readFrom(src) }
+
+type flusher struct{ rw *responseWriter }
+
+func (w flusher) Flush() { w.rw.flush() }
+
+type hijacker struct{ rw *responseWriter }
+
+func (w hijacker) Hijack() (net.Conn, *bufio.ReadWriter, error) { return w.rw.hijack() }
+
+// discarding returns writer ignoring further writes, used to render errors
+// after response has started.
+func (rw *responseWriter) discarding() *responseWriter {
+	return &responseWriter{w: rw.w, status: rw.status, size: rw.size, discard: true}
+}
+
+func (rw *responseWriter) Header() http.Header { return rw.w.Header() }
+
+func (rw *responseWriter) WriteHeader(code int) {
+	if rw.discard || rw.Committed() {
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		rw.w.WriteHeader(code)
+		return
+	}
+	rw.status = code
+	rw.w.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+	if rw.discard {
+		return len(b), nil
+	}
+	if !rw.Committed() {
+		rw.WriteHeader(http.StatusOK)
+	}
+	n, err := rw.w.Write(b)
+	rw.size += int64(n)
+	return n, err
+}
+
+func (rw *responseWriter) readFrom(src io.Reader) (int64, error) {
+	if rw.discard {
+		return io.Copy(io.Discard, src)
+	}
+	if !rw.Committed() {
+		rw.WriteHeader(http.StatusOK)
+	}
+	n, err := rw.w.(io.ReaderFrom).ReadFrom(src)
+	rw.size += n
+	return n, err
+}
+
+func (rw *responseWriter) flush() {
+	if rw.discard {
+		return
+	}
+	if !rw.Committed() {
+		rw.WriteHeader(http.StatusOK)
+	}
+	rw.w.(http.Flusher).Flush()
+}
+
+func (rw *responseWriter) hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := rw.w.(http.Hijacker).Hijack()
+	if err == nil && rw.status == 0 {
+		rw.status = http.StatusSwitchingProtocols
+	}
+	return conn, buf, err
+}
+
+func (rw *responseWriter) Status() int                 { return rw.status }
+func (rw *responseWriter) Size() int64                 { return rw.size }
+func (rw *responseWriter) Committed() bool             { return rw.status != 0 }
+func (rw *responseWriter) Unwrap() http.ResponseWriter { return rw.w }
+func (rw *responseWriter) tracked() *responseWriter    { return rw }
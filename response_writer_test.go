@@ -0,0 +1,149 @@
+package mux_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+func TestResponseWriterCommitted(t *testing.T) {
+	testCases := []struct {
+		Name      string
+		Handler   mux.HandlerFunc
+		Code      int
+		Committed bool
+		Expected  string
+	}{
+		{
+			Name: "Partial",
+			Handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.Write([]byte("partial"))
+				return errors.New("stream broken")
+			},
+			Code:      http.StatusOK,
+			Committed: true,
+			Expected:  "partial",
+		},
+		{
+			Name: "HeaderOnly",
+			Handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.WriteHeader(http.StatusAccepted)
+				return errors.New("failed after header")
+			},
+			Code:      http.StatusAccepted,
+			Committed: true,
+			Expected:  "",
+		},
+		{
+			Name: "Flushed",
+			Handler: func(w http.ResponseWriter, r *http.Request) error {
+				w.(http.Flusher).Flush()
+				return errors.New("failed after flush")
+			},
+			Code:      http.StatusOK,
+			Committed: true,
+			Expected:  "",
+		},
+		{
+			Name:      "NotCommitted",
+			Handler:   failedHandler,
+			Code:      http.StatusInternalServerError,
+			Committed: false,
+			Expected:  "internal error occured",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			var committed bool
+			errorFunc := func(err error, w http.ResponseWriter, r *http.Request) {
+				committed = mux.Committed(w)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+
+			router := mux.NewRouter(mux.WithErrorHandler(errorFunc))
+			router.HandleFunc("/", tc.Handler)
+
+			r := httptest.NewRequest("GET", "/", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+			if committed != tc.Committed {
+				t.Fatalf("got committed %v, expected %v", committed, tc.Committed)
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tc.Expected {
+				t.Fatalf("failed: got %q, expected %q", body, tc.Expected)
+			}
+		})
+	}
+}
+
+func TestResponseWriterTracking(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		rw, ok := w.(mux.ResponseWriter)
+		if !ok {
+			return errors.New("expected mux.ResponseWriter")
+		}
+		if rw.Committed() || rw.Status() != 0 {
+			return errors.New("expected fresh writer")
+		}
+		if _, err := io.Copy(w, strings.NewReader("hello")); err != nil {
+			return err
+		}
+		if rw.Status() != http.StatusOK || rw.Size() != 5 {
+			t.Errorf("got status %d and size %d", rw.Status(), rw.Size())
+		}
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("flush: %v", err)
+		}
+		return nil
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+
+	if w.Body.String() != "hello" || !w.Flushed {
+		t.Fatalf("got body %q, flushed %v", w.Body.String(), w.Flushed)
+	}
+}
+
+func TestResponseWriterInterfaces(t *testing.T) {
+	var flusher, hijacker, readerFrom bool
+	router := mux.NewRouter()
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) error {
+		_, flusher = w.(http.Flusher)
+		_, hijacker = w.(http.Hijacker)
+		_, readerFrom = w.(io.ReaderFrom)
+		return nil
+	})
+
+	r := httptest.NewRequest("GET", "/", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	if !flusher || hijacker || readerFrom {
+		t.Fatalf("got flusher %v, hijacker %v, reader from %v for recorder", flusher, hijacker, readerFrom)
+	}
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !flusher || !hijacker || !readerFrom {
+		t.Fatalf("got flusher %v, hijacker %v, reader from %v for server", flusher, hijacker, readerFrom)
+	}
+}
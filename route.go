@@ -9,14 +9,39 @@ import (
 
 // Route stores information to match a request and build URLs.
 type Route struct {
-	route   *gorillamux.Route
-	Wrapper Wrapper
+	route      *gorillamux.Route
+	Wrapper    Wrapper
+	table      *routeTable
+	namePrefix string
 }
 
 func routeWithWrapper(wr Wrapper) func(*Route) {
 	return func(r *Route) { r.Wrapper = wr }
 }
 
+func routeWithTable(t *routeTable) func(*Route) {
+	return func(r *Route) { r.table = t }
+}
+
+func routeWithNamePrefix(prefix string) func(*Route) {
+	return func(r *Route) { r.namePrefix = prefix }
+}
+
+func (r *Route) derive(route *gorillamux.Route) *Route {
+	return NewRoute(route, routeWithWrapper(r.Wrapper), routeWithTable(r.table), routeWithNamePrefix(r.namePrefix))
+}
+
+func (r *Route) setHandler(fn HandlerFunc, h http.Handler) *Route {
+	if r.table == nil {
+		if fn != nil {
+			h = r.Wrapper.HandlerFunc(fn)
+		}
+		return r.derive(r.route.Handler(h))
+	}
+	r.table.setHandler(r.route, r.Wrapper, fn, h)
+	return r.derive(r.route)
+}
+
 // NewRoute returns a new route instance.
 func NewRoute(r *gorillamux.Route, opts ...func(*Route)) *Route {
 	route := &Route{route: r}
@@ -39,7 +64,7 @@ func (r *Route) Match(req *http.Request, match *gorillamux.RouteMatch) bool {
 
 // MatcherFunc adds a custom function to be used as request matcher.
 func (r *Route) MatcherFunc(f gorillamux.MatcherFunc) *Route {
-	return NewRoute(r.route.MatcherFunc(f), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.MatcherFunc(f))
 }
 
 // GetError returns an error resulted from building the route, if any.
@@ -49,22 +74,22 @@ func (r *Route) GetError() error {
 
 // BuildOnly sets the route to never match: it is only used to build URLs.
 func (r *Route) BuildOnly() *Route {
-	return NewRoute(r.route.BuildOnly(), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.BuildOnly())
 }
 
 // Handler sets a handler for the route.
 func (r *Route) Handler(h http.Handler) *Route {
-	return NewRoute(r.route.Handler(h), routeWithWrapper(r.Wrapper))
+	return r.setHandler(nil, h)
 }
 
 // HandlerFunc sets a handler function for the route.
 func (r *Route) HandlerFunc(fn HandlerFunc) *Route {
-	return NewRoute(r.route.HandlerFunc(r.Wrapper.HandlerFunc(fn)), routeWithWrapper(r.Wrapper))
+	return r.setHandler(fn, nil)
 }
 
 // HandlerFuncBypass sets a handler function for the route.
 func (r *Route) HandlerFuncBypass(fn func(http.ResponseWriter, *http.Request)) *Route {
-	return NewRoute(r.route.HandlerFunc(fn), routeWithWrapper(r.Wrapper))
+	return r.setHandler(nil, http.HandlerFunc(fn))
 }
 
 // GetHandler returns the handler for the route, if any.
@@ -72,10 +97,11 @@ func (r *Route) GetHandler() http.Handler {
 	return r.route.GetHandler()
 }
 
-// Name sets the name for the route, used to build URLs.
+// Name sets the name for the route, used to build URLs. Name is prefixed
+// with name prefix of router, see Router.NamePrefix().
 // It is an error to call Name more than once on a route.
 func (r *Route) Name(name string) *Route {
-	return NewRoute(r.route.Name(name), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.Name(r.namePrefix + name))
 }
 
 // GetName returns the name for the route, if any.
@@ -85,58 +111,85 @@ func (r *Route) GetName() string {
 
 // Headers adds a matcher for request header values.
 func (r *Route) Headers(pairs ...string) *Route {
-	return NewRoute(r.route.Headers(pairs...), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.Headers(pairs...))
 }
 
 // HeadersRegexp accepts a sequence of key/value pairs, where the value has regex
 // support.
 func (r *Route) HeadersRegexp(pairs ...string) *Route {
-	return NewRoute(r.route.HeadersRegexp(pairs...), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.HeadersRegexp(pairs...))
 }
 
 // Host adds a matcher for the URL host.
 func (r *Route) Host(tpl string) *Route {
-	return NewRoute(r.route.Host(tpl), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.Host(tpl))
 }
 
 // Methods adds a matcher for HTTP methods.
 func (r *Route) Methods(methods ...string) *Route {
-	return NewRoute(r.route.Methods(methods...), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.Methods(methods...))
 }
 
 // Path adds a matcher for the URL path.
 func (r *Route) Path(tpl string) *Route {
-	return NewRoute(r.route.Path(tpl), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.Path(tpl))
 }
 
 // PathPrefix adds a matcher for the URL path prefix. This matches if the given
 // template is a prefix of the full URL path. See Route.Path() for details on
 // the tpl argument.
 func (r *Route) PathPrefix(tpl string) *Route {
-	return NewRoute(r.route.PathPrefix(tpl), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.PathPrefix(tpl))
 }
 
 // Queries adds a matcher for URL query values.
 func (r *Route) Queries(pairs ...string) *Route {
-	return NewRoute(r.route.Queries(pairs...), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.Queries(pairs...))
 }
 
 // Schemes adds a matcher for URL schemes.
 func (r *Route) Schemes(schemes ...string) *Route {
-	return NewRoute(r.route.Schemes(schemes...), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.Schemes(schemes...))
 }
 
 // BuildVarsFunc adds a custom function to be used to modify build variables
 // before a route's URL is built.
 func (r *Route) BuildVarsFunc(f gorillamux.BuildVarsFunc) *Route {
-	return NewRoute(r.route.BuildVarsFunc(f), routeWithWrapper(r.Wrapper))
+	return r.derive(r.route.BuildVarsFunc(f))
 }
 
-// Subrouter creates a subrouter for the route.
-func (r *Route) Subrouter() *Router {
-	return NewRouterWithMux(r.route.Subrouter(), func(rt *Router) {
+// WithWrapper sets wrapper used for route's handler and errors
+// of router middlewares for matched requests.
+func (r *Route) WithWrapper(wr Wrapper) *Route {
+	route := NewRoute(r.route, routeWithWrapper(wr), routeWithTable(r.table), routeWithNamePrefix(r.namePrefix))
+	if r.table != nil {
+		r.table.setWrapper(r.route, wr)
+	}
+	return route
+}
+
+// WithErrorHandler replaces error handler of route's `Wrapper`.
+// See Route.WithWrapper().
+func (r *Route) WithErrorHandler(fn ErrorHandlerFunc) *Route {
+	return r.WithWrapper(withErrorHandler(r.Wrapper, fn))
+}
+
+// Subrouter creates a subrouter for the route. Options are applied after
+// parent's `Wrapper` is inherited, so subrouter can handle errors in its own way.
+func (r *Route) Subrouter(opts ...func(*Router)) *Router {
+	inherit := func(rt *Router) {
 		rt.Wrapper = r.Wrapper
-	})
+		rt.namePrefix = r.namePrefix
+		rt.subrouter = true
+		if r.table != nil {
+			rt.table = r.table
+		}
+	}
+	router := newRouter(r.route.Subrouter(), append([]func(*Router){inherit}, opts...)...)
+	if len(opts) > 0 {
+		router.table.markScoped()
+	}
+	return router
 }
 
 // URL builds a URL for the route.
@@ -0,0 +1,250 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+
+	gorillamux "github.com/gorilla/mux"
+)
+
+type contextKey int
+
+const (
+	wrapperContextKey contextKey = iota
+	aroundWrapperContextKey
+	errorSlotContextKey
+	requestIDContextKey
+)
+
+// routeTable keeps wrappers and handlers of routes registered through
+// `Router` and its subrouters.
+type routeTable struct {
+	mu      sync.RWMutex
+	scoped  bool
+	entries map[*gorillamux.Route]*routeEntry
+}
+
+type routeEntry struct {
+	wrapper     Wrapper
+	handlerFunc HandlerFunc
+	handler     http.Handler
+	middlewares []routeMiddleware
+}
+
+// routeMiddleware is either `MiddlewareFunc` or `AroundFunc` wrapped by
+// route's wrapper or gorilla's `mux.MiddlewareFunc`.
+type routeMiddleware struct {
+	fn     MiddlewareFunc
+	around AroundFunc
+	bypass gorillamux.MiddlewareFunc
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{entries: make(map[*gorillamux.Route]*routeEntry)}
+}
+
+// setHandler registers handler of route. Either fn or h is set.
+func (t *routeTable) setHandler(route *gorillamux.Route, wr Wrapper, fn HandlerFunc, h http.Handler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[route]
+	if !ok {
+		entry = &routeEntry{}
+		t.entries[route] = entry
+	}
+	entry.wrapper, entry.handlerFunc, entry.handler = wr, fn, h
+	route.Handler(entry.build())
+}
+
+// use appends middlewares of route and rebuilds its handler, if any.
+func (t *routeTable) use(route *gorillamux.Route, wr Wrapper, mws ...routeMiddleware) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.entries[route]
+	if !ok {
+		entry = &routeEntry{wrapper: wr, handler: route.GetHandler()}
+		t.entries[route] = entry
+	}
+	entry.middlewares = append(entry.middlewares, mws...)
+	if h := entry.build(); h != nil {
+		route.Handler(h)
+	}
+}
+
+// middlewares returns middlewares of route in order they run.
+func (t *routeTable) middlewares(route *gorillamux.Route) []gorillamux.MiddlewareFunc {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	entry, ok := t.entries[route]
+	if !ok {
+		return nil
+	}
+	mws := make([]gorillamux.MiddlewareFunc, 0, len(entry.middlewares))
+	for _, mw := range entry.middlewares {
+		mws = append(mws, entry.middleware(mw))
+	}
+	return mws
+}
+
+// setWrapper replaces wrapper of route and rebuilds its handler.
+func (t *routeTable) setWrapper(route *gorillamux.Route, wr Wrapper) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.scoped = true
+	entry, ok := t.entries[route]
+	if !ok {
+		return
+	}
+	entry.wrapper = wr
+	if h := entry.build(); h != nil {
+		route.Handler(h)
+	}
+}
+
+func (t *routeTable) markScoped() {
+	t.mu.Lock()
+	t.scoped = true
+	t.mu.Unlock()
+}
+
+// wrapper returns wrapper of route if wrappers differ between routes.
+func (t *routeTable) wrapper(route *gorillamux.Route) Wrapper {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if !t.scoped {
+		return nil
+	}
+	if entry, ok := t.entries[route]; ok {
+		return entry.wrapper
+	}
+	return nil
+}
+
+// entryWrapper returns wrapper of registered route regardless of scoping.
+func (t *routeTable) entryWrapper(route *gorillamux.Route) Wrapper {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if entry, ok := t.entries[route]; ok {
+		return entry.wrapper
+	}
+	return nil
+}
+
+// build returns handler of route wrapped by its middlewares, so the first
+// added middleware runs first.
+func (e *routeEntry) build() http.Handler {
+	h := e.handler
+	if e.handlerFunc != nil {
+		h = e.wrapper.HandlerFunc(e.handlerFunc)
+	}
+	if h == nil {
+		return nil
+	}
+	for i := len(e.middlewares) - 1; i >= 0; i-- {
+		h = e.middleware(e.middlewares[i])(h)
+	}
+	return h
+}
+
+func (e *routeEntry) middleware(mw routeMiddleware) gorillamux.MiddlewareFunc {
+	if mw.fn != nil {
+		return e.wrapper.MiddlewareFunc(mw.fn)
+	}
+	if mw.around != nil {
+		return aroundMiddleware(e.wrapper, mw.around)
+	}
+	return mw.bypass
+}
+
+// scopeWrapper stores wrapper of matched route in request context, so
+// errors of router middlewares are handled by the most specific wrapper.
+// It is the first middleware of root router, so route matched by gorilla
+// is reused instead of matching request again.
+func (t *routeTable) scopeWrapper(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if wr := t.wrapper(gorillamux.CurrentRoute(req)); wr != nil {
+			req = req.WithContext(context.WithValue(req.Context(), wrapperContextKey, wr))
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// scopedWrapper returns wrapper stored by `scopeWrapper`, if any.
+func scopedWrapper(r *http.Request) Wrapper {
+	wr, _ := r.Context().Value(wrapperContextKey).(Wrapper)
+	return wr
+}
+
+// withErrorHandler returns copy of wr with fn as error handler. Wrappers
+// other than default one are kept and wrapped by `errorHandlerWrapper`.
+func withErrorHandler(wr Wrapper, fn ErrorHandlerFunc) Wrapper {
+	switch w := wr.(type) {
+	case *defaultWrapper:
+		cp := *w
+		cp.ErrorHandler = fn
+		return &cp
+	case *errorHandlerWrapper:
+		return &errorHandlerWrapper{Wrapper: w.Wrapper, fn: fn}
+	}
+	return &errorHandlerWrapper{Wrapper: wr, fn: fn}
+}
+
+// errorHandlerWrapper runs handlers and middlewares through custom wrapper,
+// but handles their errors by fn. Errors handled by fn are reported to
+// custom wrapper as `ErrHandled`.
+type errorHandlerWrapper struct {
+	Wrapper
+	fn ErrorHandlerFunc
+}
+
+func (wr *errorHandlerWrapper) ServeHandler(fn HandlerFunc) func(w http.ResponseWriter, r *http.Request) {
+	return wr.Wrapper.ServeHandler(func(w http.ResponseWriter, r *http.Request) error {
+		if err := fn(w, r); err != nil {
+			wr.handle(err, w, r)
+			return ErrHandled
+		}
+		return nil
+	})
+}
+
+func (wr *errorHandlerWrapper) HandlerFunc(fn HandlerFunc) http.HandlerFunc {
+	return wr.ServeHandler(fn)
+}
+
+func (wr *errorHandlerWrapper) ServeMiddleware(mwf MiddlewareFunc) func(next http.Handler) http.Handler {
+	return wr.Wrapper.ServeMiddleware(func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		ctx, err := mwf(w, r)
+		if err != nil {
+			wr.handle(err, w, r)
+			return nil, ErrHandled
+		}
+		return ctx, nil
+	})
+}
+
+func (wr *errorHandlerWrapper) MiddlewareFunc(mwf MiddlewareFunc) func(next http.Handler) http.Handler {
+	return wr.ServeMiddleware(mwf)
+}
+
+// handle passes err to enclosing `AroundFunc`, if any, or handles it.
+func (wr *errorHandlerWrapper) handle(err error, w http.ResponseWriter, r *http.Request) {
+	if slot := errorSlotFrom(r); slot != nil && !errors.Is(err, ErrHandled) {
+		slot.err = err
+		return
+	}
+	wr.HandleError(err, w, r)
+}
+
+func (wr *errorHandlerWrapper) HandleError(err error, w http.ResponseWriter, r *http.Request) {
+	if errors.Is(err, ErrHandled) {
+		return
+	}
+	if scoped := scopedWrapper(r); scoped != nil && scoped != Wrapper(wr) {
+		ctx := context.WithValue(r.Context(), wrapperContextKey, nil)
+		scoped.HandleError(err, w, r.WithContext(ctx))
+		return
+	}
+	wr.fn(err, w, r)
+}
@@ -0,0 +1,219 @@
+package mux
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a single server-sent event.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+}
+
+// EventsHandlerFunc handles server-sent events stream.
+type EventsHandlerFunc func(r *http.Request, stream *EventStream) error
+
+// EventsOption configures handler returned by `Events`.
+type EventsOption func(*eventsConfig)
+
+type eventsConfig struct {
+	keepAlive time.Duration
+	retry     time.Duration
+}
+
+// EventsKeepAlive sets interval of keep-alive comments sent while stream is
+// idle. Default is 15 seconds, zero disables them.
+func EventsKeepAlive(d time.Duration) EventsOption {
+	return func(c *eventsConfig) { c.keepAlive = d }
+}
+
+// EventsRetry sets reconnection time hint sent when stream starts.
+func EventsRetry(d time.Duration) EventsOption {
+	return func(c *eventsConfig) { c.retry = d }
+}
+
+// Events adapts fn into `HandlerFunc`. Stream starts with the first write,
+// so error returned before it is handled by `Wrapper` as usual. Errors
+// caused by client disconnect are ignored.
+func Events(fn EventsHandlerFunc, opts ...EventsOption) HandlerFunc {
+	cfg := eventsConfig{keepAlive: 15 * time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		stream := &EventStream{
+			w:    w,
+			rc:   http.NewResponseController(w),
+			ctx:  r.Context(),
+			cfg:  &cfg,
+			done: make(chan struct{}),
+
+			lastEventID: r.Header.Get("Last-Event-ID"),
+		}
+		defer stream.close()
+
+		err := fn(r, stream)
+		if ctxErr := r.Context().Err(); ctxErr != nil && errors.Is(err, ctxErr) {
+			return nil
+		}
+		return err
+	}
+}
+
+// HandleEvents registers a new route with a matcher for the URL path.
+// See Route.Path() and Route.EventsFunc().
+func (r *Router) HandleEvents(path string, fn EventsHandlerFunc, opts ...EventsOption) *Route {
+	return r.NewRoute().Path(path).EventsFunc(fn, opts...)
+}
+
+// EventsFunc sets a server-sent events handler function for the route.
+func (r *Route) EventsFunc(fn EventsHandlerFunc, opts ...EventsOption) *Route {
+	return r.HandlerFunc(Events(fn, opts...))
+}
+
+// EventStream writes server-sent events to client. It is safe for
+// concurrent use.
+type EventStream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	rc      *http.ResponseController
+	ctx     context.Context
+	cfg     *eventsConfig
+	started bool
+	done    chan struct{}
+	wg      sync.WaitGroup
+
+	lastEventID string
+}
+
+// Context returns request context, which is canceled when client disconnects.
+func (s *EventStream) Context() context.Context { return s.ctx }
+
+// LastEventID returns ID of the last event received by reconnecting client.
+func (s *EventStream) LastEventID() string { return s.lastEventID }
+
+// Started reports whether stream headers are already sent.
+func (s *EventStream) Started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.started
+}
+
+// Send writes event and flushes it to client.
+func (s *EventStream) Send(e Event) error {
+	var b strings.Builder
+	if e.ID != "" {
+		writeField(&b, "id", e.ID)
+	}
+	if e.Event != "" {
+		writeField(&b, "event", e.Event)
+	}
+	if e.Retry > 0 {
+		writeField(&b, "retry", strconv.FormatInt(e.Retry.Milliseconds(), 10))
+	}
+	for _, line := range splitLines(e.Data) {
+		b.WriteString("data: ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	return s.write(b.String())
+}
+
+// Comment writes comment line, ignored by clients.
+func (s *EventStream) Comment(text string) error {
+	var b strings.Builder
+	for _, line := range splitLines(text) {
+		b.WriteString(": ")
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	b.WriteByte('\n')
+	return s.write(b.String())
+}
+
+// Retry tells client to wait d before reconnecting.
+func (s *EventStream) Retry(d time.Duration) error {
+	return s.write("retry: " + strconv.FormatInt(d.Milliseconds(), 10) + "\n\n")
+}
+
+func (s *EventStream) write(data string) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		s.start()
+	}
+	if _, err := s.w.Write([]byte(data)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+// start writes stream headers and runs keep-alive loop. It is called with
+// mu held.
+func (s *EventStream) start() {
+	s.started = true
+	h := s.w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	s.w.WriteHeader(http.StatusOK)
+	if s.cfg.retry > 0 {
+		s.w.Write([]byte("retry: " + strconv.FormatInt(s.cfg.retry.Milliseconds(), 10) + "\n\n"))
+	}
+	if s.cfg.keepAlive > 0 {
+		s.wg.Add(1)
+		go s.keepAlive(s.cfg.keepAlive)
+	}
+}
+
+func (s *EventStream) keepAlive(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.write(": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *EventStream) close() {
+	close(s.done)
+	s.wg.Wait()
+}
+
+// lineBreaks normalizes all line terminators of event stream format.
+var lineBreaks = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+// splitLines splits s by CRLF, LF or lone CR.
+func splitLines(s string) []string {
+	return strings.Split(lineBreaks.Replace(s), "\n")
+}
+
+func writeField(b *strings.Builder, name, value string) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	b.WriteString(name)
+	b.WriteString(": ")
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
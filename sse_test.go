@@ -0,0 +1,137 @@
+package mux_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikarik/mux"
+)
+
+func TestEvents(t *testing.T) {
+	testCases := []struct {
+		Name        string
+		Handler     mux.EventsHandlerFunc
+		Options     []mux.EventsOption
+		LastEventID string
+		Code        int
+		ContentType string
+		Expected    string
+	}{
+		{
+			Name: "Events",
+			Handler: func(r *http.Request, stream *mux.EventStream) error {
+				if err := stream.Send(mux.Event{ID: "1", Event: "greeting", Data: "hello\nworld"}); err != nil {
+					return err
+				}
+				return stream.Send(mux.Event{Data: "bye"})
+			},
+			Options:     []mux.EventsOption{mux.EventsRetry(3 * time.Second)},
+			Code:        http.StatusOK,
+			ContentType: "text/event-stream",
+			Expected:    "retry: 3000\n\nid: 1\nevent: greeting\ndata: hello\ndata: world\n\ndata: bye\n\n",
+		},
+		{
+			Name: "LastEventID",
+			Handler: func(r *http.Request, stream *mux.EventStream) error {
+				return stream.Send(mux.Event{ID: "43", Data: "resumed after " + stream.LastEventID()})
+			},
+			LastEventID: "42",
+			Code:        http.StatusOK,
+			ContentType: "text/event-stream",
+			Expected:    "id: 43\ndata: resumed after 42\n\n",
+		},
+		{
+			Name: "LineBreaks",
+			Handler: func(r *http.Request, stream *mux.EventStream) error {
+				if err := stream.Comment("a\rid: 1"); err != nil {
+					return err
+				}
+				return stream.Send(mux.Event{Data: "a\rid: 99\r\nb\nc"})
+			},
+			Code:        http.StatusOK,
+			ContentType: "text/event-stream",
+			Expected:    ": a\n: id: 1\n\ndata: a\ndata: id: 99\ndata: b\ndata: c\n\n",
+		},
+		{
+			Name: "KeepAlive",
+			Handler: func(r *http.Request, stream *mux.EventStream) error {
+				if err := stream.Comment("connected"); err != nil {
+					return err
+				}
+				time.Sleep(50 * time.Millisecond)
+				return nil
+			},
+			Options:     []mux.EventsOption{mux.EventsKeepAlive(10 * time.Millisecond)},
+			Code:        http.StatusOK,
+			ContentType: "text/event-stream",
+			Expected:    ": connected\n\n: keep-alive\n\n",
+		},
+		{
+			Name: "ErrorBeforeFirstEvent",
+			Handler: func(r *http.Request, stream *mux.EventStream) error {
+				return mux.ErrUnauthorized
+			},
+			Code:        http.StatusUnauthorized,
+			ContentType: "application/json",
+			Expected:    `{"code":401,"message":"Unauthorized"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+			router.HandleEvents("/events", tc.Handler, tc.Options...)
+
+			r := httptest.NewRequest("GET", "/events", nil)
+			if tc.LastEventID != "" {
+				r.Header.Set("Last-Event-ID", tc.LastEventID)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != tc.ContentType {
+				t.Fatalf("got content type %s, expected %s", ct, tc.ContentType)
+			}
+			if !strings.HasPrefix(w.Body.String(), tc.Expected) {
+				t.Fatalf("failed: got %q, expected %q", w.Body.String(), tc.Expected)
+			}
+		})
+	}
+}
+
+func TestEventsClientDisconnect(t *testing.T) {
+	var handled error
+	errorFunc := func(err error, w http.ResponseWriter, r *http.Request) { handled = err }
+
+	router := mux.NewRouter(mux.WithErrorHandler(errorFunc))
+	router.HandleEvents("/events", func(r *http.Request, stream *mux.EventStream) error {
+		if err := stream.Send(mux.Event{Data: "first"}); err != nil {
+			return err
+		}
+		<-stream.Context().Done()
+		return stream.Send(mux.Event{Data: "second"})
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/events", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	router.ServeHTTP(w, r)
+
+	if handled != nil {
+		t.Fatalf("got %v, expected disconnect to be ignored", handled)
+	}
+	if w.Body.String() != "data: first\n\n" {
+		t.Fatalf("got %q", w.Body.String())
+	}
+}
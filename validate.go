@@ -0,0 +1,370 @@
+package mux
+
+import (
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ValidationRule checks field value against rule parameter, e.g. "10" for
+// `min=10`. Returned error message is reported as field violation.
+type ValidationRule func(v reflect.Value, param string) error
+
+// Validator checks struct fields by `validate` tags such as
+// `validate:"required,min=1,max=100,email,oneof=a b"`. Rules apply to zero
+// values too, unless tag contains `omitempty`. Nil pointers are checked
+// only by `required` rule. Tags are parsed once per struct type, unknown
+// rules and invalid parameters are returned as plain error.
+type Validator struct {
+	mu    sync.RWMutex
+	rules map[string]ValidationRule
+	types map[reflect.Type]*structRules
+}
+
+// NewValidator returns validator with built-in rules: required, min, max,
+// len, email and oneof.
+func NewValidator() *Validator {
+	return &Validator{
+		rules: map[string]ValidationRule{
+			"required": validateRequired,
+			"min":      validateMin,
+			"max":      validateMax,
+			"len":      validateLen,
+			"email":    validateEmail,
+			"oneof":    validateOneOf,
+		},
+		types: make(map[reflect.Type]*structRules),
+	}
+}
+
+// DefaultValidator is used by `Validate`, `Bind` and `JSON`.
+var DefaultValidator = NewValidator()
+
+// RegisterValidation registers custom rule in `DefaultValidator`.
+func RegisterValidation(name string, rule ValidationRule) {
+	DefaultValidator.Register(name, rule)
+}
+
+// Validate checks v with `DefaultValidator`.
+func Validate(v interface{}) error {
+	return DefaultValidator.Validate(v)
+}
+
+// Register registers custom rule, replacing existing one with the same name.
+func (val *Validator) Register(name string, rule ValidationRule) {
+	val.mu.Lock()
+	val.rules[name] = rule
+	val.types = make(map[reflect.Type]*structRules)
+	val.mu.Unlock()
+}
+
+// Validate checks struct or pointer to struct v. Violations are returned as
+// `HTTPError` with status 422 and field violations as `Details`.
+func (val *Validator) Validate(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	rules, err := val.structRules(rv.Type())
+	if err != nil {
+		return err
+	}
+	var violations FieldErrors
+	rules.validate(rv, "", &violations)
+	return violations.Err(http.StatusUnprocessableEntity, "Validation failed")
+}
+
+// structRules holds parsed rules of struct type.
+type structRules struct {
+	fields []fieldRules
+	err    error
+}
+
+type fieldRules struct {
+	index     int
+	name      string
+	anonymous bool
+	omitempty bool
+	rules     []rule
+	nested    *structRules
+}
+
+type rule struct {
+	name  string
+	param string
+	fn    ValidationRule
+}
+
+// structRules returns cached rules of t, parsing them on first use.
+func (val *Validator) structRules(t reflect.Type) (*structRules, error) {
+	val.mu.RLock()
+	sr, ok := val.types[t]
+	val.mu.RUnlock()
+	if ok {
+		return sr, sr.err
+	}
+
+	val.mu.Lock()
+	defer val.mu.Unlock()
+	sr = val.parseStruct(t)
+	return sr, sr.err
+}
+
+// parseStruct parses rules of t and nested struct types. Caller holds lock.
+func (val *Validator) parseStruct(t reflect.Type) *structRules {
+	if sr, ok := val.types[t]; ok {
+		return sr
+	}
+	sr := &structRules{}
+	val.types[t] = sr
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		fr := fieldRules{index: i, name: fieldName(field), anonymous: field.Anonymous}
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			if err := val.parseTag(&fr, field.Type, tag); err != nil {
+				sr.err = fmt.Errorf("mux: field %s.%s: %w", t.Name(), field.Name, err)
+				return sr
+			}
+		}
+		if nt := nestedStruct(field.Type); nt != nil {
+			nested := val.parseStruct(nt)
+			if nested.err != nil {
+				sr.err = nested.err
+				return sr
+			}
+			fr.nested = nested
+		}
+		if fr.rules != nil || fr.nested != nil {
+			sr.fields = append(sr.fields, fr)
+		}
+	}
+	return sr
+}
+
+func (val *Validator) parseTag(fr *fieldRules, t reflect.Type, tag string) error {
+	for _, item := range strings.Split(tag, ",") {
+		name, param := item, ""
+		if i := strings.IndexByte(item, '='); i >= 0 {
+			name, param = item[:i], item[i+1:]
+		}
+		if name == "omitempty" {
+			fr.omitempty = true
+			continue
+		}
+		fn, ok := val.rules[name]
+		if !ok {
+			return fmt.Errorf("unknown validation rule %q", name)
+		}
+		if check, ok := ruleChecks[name]; ok {
+			if err := check(derefType(t), param); err != nil {
+				return fmt.Errorf("validation rule %q: %w", name, err)
+			}
+		}
+		fr.rules = append(fr.rules, rule{name: name, param: param, fn: fn})
+	}
+	return nil
+}
+
+// ruleChecks check parameters and field types of built-in rules.
+var ruleChecks = map[string]func(t reflect.Type, param string) error{
+	"min": checkSizeRule,
+	"max": checkSizeRule,
+	"len": checkSizeRule,
+}
+
+func checkSizeRule(t reflect.Type, param string) error {
+	if _, err := strconv.ParseFloat(param, 64); err != nil {
+		return fmt.Errorf("parameter %q is not a number", param)
+	}
+	if _, _, ok := size(reflect.Zero(t)); !ok {
+		return fmt.Errorf("can't be used on %s", t)
+	}
+	return nil
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// nestedStruct returns struct type validated recursively for field of type t.
+func nestedStruct(t reflect.Type) reflect.Type {
+	t = derefType(t)
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = derefType(t.Elem())
+	}
+	if t.Kind() == reflect.Struct && t != timeType {
+		return t
+	}
+	return nil
+}
+
+func (sr *structRules) validate(v reflect.Value, prefix string, violations *FieldErrors) {
+	for _, fr := range sr.fields {
+		fv := v.Field(fr.index)
+		path := prefix
+		if !fr.anonymous {
+			path = joinPath(prefix, fr.name)
+		}
+		fr.validate(fv, path, violations)
+		if fr.nested != nil {
+			validateNested(fr.nested, fv, path, violations)
+		}
+	}
+}
+
+func validateNested(sr *structRules, v reflect.Value, path string, violations *FieldErrors) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		sr.validate(v, path, violations)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validateNested(sr, v.Index(i), fmt.Sprintf("%s[%d]", path, i), violations)
+		}
+	}
+}
+
+func (fr *fieldRules) validate(v reflect.Value, path string, violations *FieldErrors) {
+	if fr.omitempty && isZero(v) {
+		return
+	}
+	for _, r := range fr.rules {
+		if r.name != "required" && v.Kind() == reflect.Ptr && v.IsNil() {
+			continue
+		}
+		if err := r.fn(indirect(v), r.param); err != nil {
+			violations.Add(path, r.name, err.Error())
+			if r.name == "required" {
+				return
+			}
+		}
+	}
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "query", "path", "header"} {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+				return name
+			}
+		}
+	}
+	return field.Name
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func validateRequired(v reflect.Value, param string) error {
+	if isZero(v) {
+		return fmt.Errorf("is required")
+	}
+	return nil
+}
+
+// size returns number compared by min, max and len rules.
+func size(v reflect.Value) (float64, string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters", true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(v.Len()), " items", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), "", true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), "", true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), "", true
+	}
+	return 0, "", false
+}
+
+func compareSize(v reflect.Value, param string, cmp func(n, limit float64) bool, format string) error {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return fmt.Errorf("has invalid rule parameter %q", param)
+	}
+	n, unit, ok := size(v)
+	if !ok {
+		return fmt.Errorf("can't be compared by size")
+	}
+	if !cmp(n, limit) {
+		return fmt.Errorf(format, param+unit)
+	}
+	return nil
+}
+
+func validateMin(v reflect.Value, param string) error {
+	return compareSize(v, param, func(n, limit float64) bool { return n >= limit }, "must be at least %s")
+}
+
+func validateMax(v reflect.Value, param string) error {
+	return compareSize(v, param, func(n, limit float64) bool { return n <= limit }, "must be at most %s")
+}
+
+func validateLen(v reflect.Value, param string) error {
+	return compareSize(v, param, func(n, limit float64) bool { return n == limit }, "must be exactly %s")
+}
+
+func validateEmail(v reflect.Value, param string) error {
+	s := fmt.Sprint(v.Interface())
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return fmt.Errorf("must be a valid email address")
+	}
+	return nil
+}
+
+func validateOneOf(v reflect.Value, param string) error {
+	s := fmt.Sprint(v.Interface())
+	options := strings.Fields(param)
+	for _, option := range options {
+		if s == option {
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(options, ", "))
+}
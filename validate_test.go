@@ -0,0 +1,264 @@
+package mux_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/danikarik/mux"
+)
+
+type address struct {
+	City string `json:"city" validate:"required"`
+}
+
+type signupRequest struct {
+	Name      string    `json:"name" validate:"required,min=2,max=10"`
+	Email     string    `json:"email" validate:"required,email"`
+	Age       int       `json:"age" validate:"omitempty,min=18,max=120"`
+	Role      string    `json:"role" validate:"omitempty,oneof=admin user"`
+	Tags      []string  `json:"tags" validate:"max=2"`
+	Address   *address  `json:"address"`
+	Addresses []address `json:"addresses"`
+}
+
+type orderRequest struct {
+	Qty  int     `json:"qty" validate:"min=1,max=100"`
+	Role string  `json:"role" validate:"oneof=admin user"`
+	Note *string `json:"note" validate:"max=10"`
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Value   interface{}
+		Details []mux.FieldError
+	}{
+		{
+			Name:  "OK",
+			Value: signupRequest{Name: "john", Email: "john@example.com", Age: 30, Role: "admin"},
+		},
+		{
+			Name:  "ZeroNumber",
+			Value: orderRequest{Role: "user"},
+			Details: []mux.FieldError{
+				{Path: "qty", Code: "min", Message: "must be at least 1"},
+			},
+		},
+		{
+			Name:  "ZeroString",
+			Value: orderRequest{Qty: 1},
+			Details: []mux.FieldError{
+				{Path: "role", Code: "oneof", Message: "must be one of admin, user"},
+			},
+		},
+		{
+			Name:  "Optional",
+			Value: signupRequest{Name: "john", Email: "john@example.com"},
+		},
+		{
+			Name:  "Required",
+			Value: signupRequest{},
+			Details: []mux.FieldError{
+				{Path: "name", Code: "required", Message: "is required"},
+				{Path: "email", Code: "required", Message: "is required"},
+			},
+		},
+		{
+			Name: "Invalid",
+			Value: signupRequest{
+				Name:  "j",
+				Email: "john",
+				Age:   12,
+				Role:  "root",
+				Tags:  []string{"a", "b", "c"},
+			},
+			Details: []mux.FieldError{
+				{Path: "name", Code: "min", Message: "must be at least 2 characters"},
+				{Path: "email", Code: "email", Message: "must be a valid email address"},
+				{Path: "age", Code: "min", Message: "must be at least 18"},
+				{Path: "role", Code: "oneof", Message: "must be one of admin, user"},
+				{Path: "tags", Code: "max", Message: "must be at most 2 items"},
+			},
+		},
+		{
+			Name: "Nested",
+			Value: signupRequest{
+				Name:      "john",
+				Email:     "john@example.com",
+				Address:   &address{},
+				Addresses: []address{{City: "Almaty"}, {}},
+			},
+			Details: []mux.FieldError{
+				{Path: "address.city", Code: "required", Message: "is required"},
+				{Path: "addresses[1].city", Code: "required", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := mux.Validate(tc.Value)
+			if tc.Details == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var e *mux.HTTPError
+			if !errors.As(err, &e) {
+				t.Fatalf("failed: got %v, expected *HTTPError", err)
+			}
+			if e.Code != http.StatusUnprocessableEntity {
+				t.Fatal(newStatusError(e.Code, http.StatusUnprocessableEntity))
+			}
+			if !reflect.DeepEqual(e.Details, tc.Details) {
+				t.Fatalf("failed: got %+v, expected %+v", e.Details, tc.Details)
+			}
+		})
+	}
+}
+
+func TestValidatorRegister(t *testing.T) {
+	type request struct {
+		Slug string `validate:"required,slug"`
+	}
+
+	v := mux.NewValidator()
+	v.Register("slug", func(v reflect.Value, param string) error {
+		if strings.ContainsAny(v.String(), " /") {
+			return fmt.Errorf("must be a slug")
+		}
+		return nil
+	})
+
+	if err := v.Validate(request{Slug: "hello-world"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var e *mux.HTTPError
+	if err := v.Validate(request{Slug: "hello world"}); !errors.As(err, &e) {
+		t.Fatalf("failed: got %v, expected *HTTPError", err)
+	}
+	expected := []mux.FieldError{{Path: "Slug", Code: "slug", Message: "must be a slug"}}
+	if !reflect.DeepEqual(e.Details, expected) {
+		t.Fatalf("failed: got %+v, expected %+v", e.Details, expected)
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	signup := func(ctx context.Context, req signupRequest) (signupRequest, error) {
+		return req, nil
+	}
+
+	router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+	router.Path("/signup").Methods("POST").HandlerFunc(mux.JSON(signup))
+
+	r := httptest.NewRequest("POST", "/signup", strings.NewReader(`{"name":"john","email":"john"}`))
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, r)
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatal(newStatusError(resp.StatusCode, http.StatusUnprocessableEntity))
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	expected := `{"code":422,"message":"Validation failed","details":[{"path":"email","code":"email","message":"must be a valid email address"}]}`
+	if strings.TrimSpace(string(data)) != expected {
+		t.Fatalf("failed: got %s, expected %s", string(data), expected)
+	}
+}
+
+func TestValidateBind(t *testing.T) {
+	type request struct {
+		Page int `query:"page" validate:"min=1"`
+	}
+
+	router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+	router.HandleFunc("/items", func(w http.ResponseWriter, r *http.Request) error {
+		var req request
+		if err := mux.Bind(r, &req); err != nil {
+			return err
+		}
+		return json.NewEncoder(w).Encode(req)
+	})
+
+	testCases := []struct {
+		Name string
+		URL  string
+		Code int
+	}{
+		{Name: "OK", URL: "/items?page=2", Code: http.StatusOK},
+		{Name: "Invalid", URL: "/items?page=-1", Code: http.StatusUnprocessableEntity},
+		{Name: "Malformed", URL: "/items?page=x", Code: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest("GET", tc.URL, nil))
+			if w.Code != tc.Code {
+				t.Fatal(newStatusError(w.Code, tc.Code))
+			}
+		})
+	}
+}
+
+func TestValidateInvalidTags(t *testing.T) {
+	testCases := []struct {
+		Name  string
+		Value interface{}
+	}{
+		{
+			Name: "UnknownRule",
+			Value: struct {
+				A string `validate:"slug"`
+			}{},
+		},
+		{
+			Name: "InvalidParameter",
+			Value: struct {
+				A int `validate:"min=one"`
+			}{},
+		},
+		{
+			Name: "SizeRuleKind",
+			Value: struct {
+				A bool `validate:"max=1"`
+			}{},
+		},
+		{
+			Name: "Nested",
+			Value: struct {
+				Items []struct {
+					A bool `validate:"len=1"`
+				}
+			}{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := mux.NewValidator().Validate(tc.Value)
+			var e *mux.HTTPError
+			if err == nil || errors.As(err, &e) {
+				t.Fatalf("failed: got %v, expected tag error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package mux
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Var returns route variable converted to T. Missing or malformed variable
+// is reported as `HTTPError` with status 400, so handler can return it as is.
+// Slices are filled by comma separated items. Types which can't be converted
+// from string, e.g. maps and structs, are reported as plain error.
+func Var[T any](r *http.Request, name string) (T, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if err := checkType(rv.Type()); err != nil {
+		return v, err
+	}
+	s, ok := Vars(r)[name]
+	if !ok {
+		return v, varError(name, "missing", "is missing")
+	}
+	if err := setValue(rv, s); err != nil {
+		return v, varError(name, "type", err.Error())
+	}
+	return v, nil
+}
+
+// VarInt returns route variable as int. See Var().
+func VarInt(r *http.Request, name string) (int, error) {
+	return Var[int](r, name)
+}
+
+// VarInt64 returns route variable as int64. See Var().
+func VarInt64(r *http.Request, name string) (int64, error) {
+	return Var[int64](r, name)
+}
+
+// VarUUID returns route variable in lower case after checking it is
+// a UUID in canonical textual form. See Var().
+func VarUUID(r *http.Request, name string) (string, error) {
+	s, err := Var[string](r, name)
+	if err != nil {
+		return "", err
+	}
+	if !isUUID(s) {
+		return "", varError(name, "type", "must be a UUID")
+	}
+	return strings.ToLower(s), nil
+}
+
+// VarTime returns route variable parsed with layout. Empty layout means
+// `time.RFC3339`. See Var().
+func VarTime(r *http.Request, name, layout string) (time.Time, error) {
+	s, err := Var[string](r, name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, varError(name, "type", fmt.Sprintf("must be a time in %q format", layout))
+	}
+	return t, nil
+}
+
+func varError(name, code, message string) *HTTPError {
+	return NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Path variable %q %s", name, message)).
+		WithFieldError(name, code, message)
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package mux_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danikarik/mux"
+)
+
+func TestVarAccessors(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Path     string
+		Handler  func(r *http.Request) (interface{}, error)
+		Code     int
+		Expected string
+	}{
+		{
+			Name:     "Int",
+			Path:     "/42",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.VarInt(r, "v") },
+			Code:     http.StatusOK,
+			Expected: "42",
+		},
+		{
+			Name:     "InvalidInt",
+			Path:     "/abc",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.VarInt(r, "v") },
+			Code:     http.StatusBadRequest,
+			Expected: `Path variable "v" must be an integer`,
+		},
+		{
+			Name:     "Missing",
+			Path:     "/42",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.VarInt64(r, "id") },
+			Code:     http.StatusBadRequest,
+			Expected: `Path variable "id" is missing`,
+		},
+		{
+			Name:     "UUID",
+			Path:     "/6BA7B810-9DAD-11D1-80B4-00C04FD430C8",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.VarUUID(r, "v") },
+			Code:     http.StatusOK,
+			Expected: "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+		},
+		{
+			Name:     "InvalidUUID",
+			Path:     "/6ba7b810-9dad",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.VarUUID(r, "v") },
+			Code:     http.StatusBadRequest,
+			Expected: `Path variable "v" must be a UUID`,
+		},
+		{
+			Name: "Time",
+			Path: "/2019-09-29",
+			Handler: func(r *http.Request) (interface{}, error) {
+				t, err := mux.VarTime(r, "v", "2006-01-02")
+				return t.Weekday(), err
+			},
+			Code:     http.StatusOK,
+			Expected: time.Sunday.String(),
+		},
+		{
+			Name:     "Generic",
+			Path:     "/true",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.Var[bool](r, "v") },
+			Code:     http.StatusOK,
+			Expected: "true",
+		},
+		{
+			Name:     "GenericInvalid",
+			Path:     "/300",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.Var[uint8](r, "v") },
+			Code:     http.StatusBadRequest,
+			Expected: `Path variable "v" must be a non-negative integer`,
+		},
+		{
+			Name:     "GenericSlice",
+			Path:     "/1,2,3",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.Var[[]int](r, "v") },
+			Code:     http.StatusOK,
+			Expected: "[1 2 3]",
+		},
+		{
+			Name:     "GenericSliceInvalid",
+			Path:     "/1,x",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.Var[[]int](r, "v") },
+			Code:     http.StatusBadRequest,
+			Expected: `Path variable "v" must be an integer`,
+		},
+		{
+			Name:     "GenericUnsupported",
+			Path:     "/x",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.Var[map[string]string](r, "v") },
+			Code:     http.StatusInternalServerError,
+			Expected: "Internal Server Error",
+		},
+		{
+			Name:     "GenericUnsupportedStruct",
+			Path:     "/x",
+			Handler:  func(r *http.Request) (interface{}, error) { return mux.Var[struct{ A int }](r, "v") },
+			Code:     http.StatusInternalServerError,
+			Expected: "Internal Server Error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			router := mux.NewRouter(mux.WithErrorHandler(mux.NegotiateErrorFunc))
+			router.HandleFunc("/{v}", func(w http.ResponseWriter, r *http.Request) error {
+				v, err := tc.Handler(r)
+				if err != nil {
+					return err
+				}
+				fmt.Fprint(w, v)
+				return nil
+			})
+
+			r := httptest.NewRequest("GET", tc.Path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+			resp := w.Result()
+
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+
+			if tc.Code == http.StatusOK {
+				if w.Body.String() != tc.Expected {
+					t.Fatalf("got %s, expected %s", w.Body.String(), tc.Expected)
+				}
+				return
+			}
+
+			var e mux.HTTPError
+			if err := json.NewDecoder(resp.Body).Decode(&e); err != nil {
+				t.Fatal(err)
+			}
+			if e.Message != tc.Expected {
+				t.Fatalf("got %s, expected %s", e.Message, tc.Expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,569 @@
+package mux
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is a type of WebSocket data message.
+type MessageType int
+
+// WebSocket data message types.
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// WebSocket close codes defined by RFC 6455.
+const (
+	CloseNormalClosure           = 1000
+	CloseGoingAway               = 1001
+	CloseProtocolError           = 1002
+	CloseUnsupportedData         = 1003
+	CloseNoStatusReceived        = 1005
+	CloseAbnormalClosure         = 1006
+	CloseInvalidFramePayloadData = 1007
+	ClosePolicyViolation         = 1008
+	CloseMessageTooBig           = 1009
+	CloseInternalServerErr       = 1011
+)
+
+const (
+	webSocketGUID         = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxControlPayload     = 125
+	defaultWebSocketLimit = 32 << 20
+	// maxWebSocketLimit bounds messages when read limit is disabled.
+	maxWebSocketLimit = 1 << 30
+)
+
+// ErrWebSocketClosed is returned when writing to closed connection.
+var ErrWebSocketClosed = errors.New("mux: websocket connection closed")
+
+// CloseError is returned by `WebSocketConn.ReadMessage` when connection is
+// closed. Returned from `WebSocketHandlerFunc`, it sets close code sent to
+// client.
+type CloseError struct {
+	Code int
+	Text string
+}
+
+// Error implements error interface.
+func (e *CloseError) Error() string {
+	if e.Text == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d: %s", e.Code, e.Text)
+}
+
+// WebSocketHandlerFunc handles upgraded WebSocket connection. Request holds
+// route variables and context values set by middlewares.
+type WebSocketHandlerFunc func(r *http.Request, conn *WebSocketConn) error
+
+// WebSocketOption configures handler returned by `WebSocket`.
+type WebSocketOption func(*webSocketConfig)
+
+type webSocketConfig struct {
+	checkOrigin   func(r *http.Request) bool
+	subprotocols  []string
+	readLimit     int64
+	beforeUpgrade MiddlewareFunc
+}
+
+// WebSocketCheckOrigin sets origin check. By default `Origin` header, if
+// present, must match request host.
+func WebSocketCheckOrigin(fn func(r *http.Request) bool) WebSocketOption {
+	return func(c *webSocketConfig) { c.checkOrigin = fn }
+}
+
+// WebSocketSubprotocols sets supported subprotocols in order of preference.
+func WebSocketSubprotocols(protocols ...string) WebSocketOption {
+	return func(c *webSocketConfig) { c.subprotocols = protocols }
+}
+
+// WebSocketReadLimit sets maximum size of incoming message. Default is 32MB.
+// Zero or negative n falls back to hard limit of 1GB.
+func WebSocketReadLimit(n int64) WebSocketOption {
+	return func(c *webSocketConfig) {
+		if n <= 0 {
+			n = maxWebSocketLimit
+		}
+		c.readLimit = n
+	}
+}
+
+// WebSocketBeforeUpgrade sets mwf called after handshake is checked and
+// before connection is upgraded, e.g. to authorize access to resource from
+// route variables. Its error is returned by handler, so it is handled by
+// `Wrapper`, and its context is passed to `WebSocketHandlerFunc`.
+func WebSocketBeforeUpgrade(mwf MiddlewareFunc) WebSocketOption {
+	return func(c *webSocketConfig) { c.beforeUpgrade = mwf }
+}
+
+// WebSocket adapts fn into `HandlerFunc`. Failed handshake is returned as
+// `HTTPError`, so it is handled by `Wrapper` like any other error. Handler
+// can reject request before upgrade, see WebSocketBeforeUpgrade(). After
+// upgrade, error returned by fn closes connection with matching close code:
+// `CloseError` code, policy violation for client `HTTPError` and internal
+// error otherwise. Such errors are still passed to `Wrapper` for logging,
+// except normal closure and closing initiated by client.
+func WebSocket(fn WebSocketHandlerFunc, opts ...WebSocketOption) HandlerFunc {
+	cfg := webSocketConfig{checkOrigin: sameOrigin, readLimit: defaultWebSocketLimit}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(w http.ResponseWriter, r *http.Request) error {
+		conn, r, err := upgrade(w, r, &cfg)
+		if err != nil {
+			return err
+		}
+		return conn.finish(fn(r, conn))
+	}
+}
+
+// HandleWebSocket registers a new route with a matcher for the URL path.
+// See Route.Path() and Route.WebSocketFunc().
+func (r *Router) HandleWebSocket(path string, fn WebSocketHandlerFunc, opts ...WebSocketOption) *Route {
+	return r.NewRoute().Path(path).WebSocketFunc(fn, opts...)
+}
+
+// WebSocketFunc sets a WebSocket handler function for the route.
+func (r *Route) WebSocketFunc(fn WebSocketHandlerFunc, opts ...WebSocketOption) *Route {
+	return r.HandlerFunc(WebSocket(fn, opts...))
+}
+
+func upgrade(w http.ResponseWriter, r *http.Request, cfg *webSocketConfig) (*WebSocketConn, *http.Request, error) {
+	if r.Method != http.MethodGet {
+		return nil, nil, NewHTTPError(http.StatusMethodNotAllowed, "WebSocket handshake requires GET method").
+			WithHeader("Allow", http.MethodGet)
+	}
+	if !headerContainsToken(r.Header, "Connection", "upgrade") || !headerContainsToken(r.Header, "Upgrade", "websocket") {
+		return nil, nil, NewHTTPError(http.StatusUpgradeRequired, "WebSocket upgrade required").
+			WithHeader("Upgrade", "websocket")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		return nil, nil, NewHTTPError(http.StatusUpgradeRequired, "Unsupported WebSocket version").
+			WithHeader("Sec-WebSocket-Version", "13")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 16 {
+		return nil, nil, NewHTTPError(http.StatusBadRequest, "Invalid Sec-WebSocket-Key header")
+	}
+	if !cfg.checkOrigin(r) {
+		return nil, nil, NewHTTPError(http.StatusForbidden, "Origin is not allowed")
+	}
+	if cfg.beforeUpgrade != nil {
+		ctx, err := cfg.beforeUpgrade(w, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ctx != nil {
+			r = r.WithContext(ctx)
+		}
+	}
+	subprotocol := selectSubprotocol(r, cfg.subprotocols)
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	if brw.Reader.Buffered() == 0 {
+		brw = nil
+	}
+
+	var b strings.Builder
+	b.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	b.WriteString("Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n")
+	if subprotocol != "" {
+		b.WriteString("Sec-WebSocket-Protocol: " + subprotocol + "\r\n")
+	}
+	b.WriteString("\r\n")
+	if _, err := netConn.Write([]byte(b.String())); err != nil {
+		netConn.Close()
+		return nil, nil, err
+	}
+
+	conn := &WebSocketConn{
+		conn:        netConn,
+		readLimit:   cfg.readLimit,
+		subprotocol: subprotocol,
+	}
+	if brw != nil {
+		conn.br = brw.Reader
+	} else {
+		conn.br = bufio.NewReader(netConn)
+	}
+	return conn, r, nil
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(u.Host, r.Host)
+}
+
+func selectSubprotocol(r *http.Request, supported []string) string {
+	for _, offered := range headerTokens(r.Header, "Sec-WebSocket-Protocol") {
+		for _, p := range supported {
+			if offered == p {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+func headerTokens(h http.Header, name string) []string {
+	var tokens []string
+	for _, value := range h.Values(name) {
+		for _, token := range strings.Split(value, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				tokens = append(tokens, token)
+			}
+		}
+	}
+	return tokens
+}
+
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, t := range headerTokens(h, name) {
+		if strings.EqualFold(t, token) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebSocketConn is an upgraded WebSocket connection. Reads must be done from
+// a single goroutine, writes are safe for concurrent use.
+type WebSocketConn struct {
+	conn        net.Conn
+	br          *bufio.Reader
+	readLimit   int64
+	subprotocol string
+
+	mu         sync.Mutex
+	closeSent  bool
+	peerClosed bool
+}
+
+// Subprotocol returns negotiated subprotocol, if any.
+func (c *WebSocketConn) Subprotocol() string { return c.subprotocol }
+
+// RemoteAddr returns remote network address.
+func (c *WebSocketConn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// SetReadDeadline sets deadline for future reads.
+func (c *WebSocketConn) SetReadDeadline(t time.Time) error { return c.conn.SetReadDeadline(t) }
+
+// SetWriteDeadline sets deadline for future writes.
+func (c *WebSocketConn) SetWriteDeadline(t time.Time) error { return c.conn.SetWriteDeadline(t) }
+
+// ReadMessage reads next data message. Pings are answered automatically.
+// When connection is closed by peer or because of protocol violation,
+// `CloseError` is returned. Other read errors, e.g. expired deadline, are
+// returned as is.
+func (c *WebSocketConn) ReadMessage() (MessageType, []byte, error) {
+	var (
+		typ     MessageType
+		message []byte
+	)
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, c.failRead(err)
+		}
+
+		switch opcode {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			continue
+		case opClose:
+			return 0, nil, c.handleClose(payload)
+		case opText, opBinary:
+			if typ != 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected data frame")
+			}
+			typ = MessageType(opcode)
+		case opContinuation:
+			if typ == 0 {
+				return 0, nil, c.fail(CloseProtocolError, "unexpected continuation frame")
+			}
+		default:
+			return 0, nil, c.fail(CloseProtocolError, "unknown opcode")
+		}
+
+		if int64(len(message)+len(payload)) > c.limit() {
+			return 0, nil, c.fail(CloseMessageTooBig, "message too big")
+		}
+		message = append(message, payload...)
+		if fin {
+			break
+		}
+	}
+	if typ == TextMessage && !utf8.Valid(message) {
+		return 0, nil, c.fail(CloseInvalidFramePayloadData, "invalid UTF-8")
+	}
+	return typ, message, nil
+}
+
+// WriteMessage writes data message as a single frame.
+func (c *WebSocketConn) WriteMessage(typ MessageType, data []byte) error {
+	if typ != TextMessage && typ != BinaryMessage {
+		return fmt.Errorf("mux: unsupported message type %d", typ)
+	}
+	return c.writeFrame(byte(typ), data)
+}
+
+// WriteText writes text message.
+func (c *WebSocketConn) WriteText(s string) error {
+	return c.WriteMessage(TextMessage, []byte(s))
+}
+
+// Ping writes ping control frame.
+func (c *WebSocketConn) Ping(data []byte) error {
+	if len(data) > maxControlPayload {
+		return errors.New("mux: ping payload too large")
+	}
+	return c.writeFrame(opPing, data)
+}
+
+// Close sends close frame with code and reason and closes connection.
+// Codes which must not be sent, such as 1005 and 1006, are refused and
+// connection is closed without close frame.
+func (c *WebSocketConn) Close(code int, reason string) error {
+	if !validCloseCode(code) {
+		c.conn.Close()
+		return invalidCloseCode(code)
+	}
+	c.mu.Lock()
+	sent := c.closeSent
+	c.mu.Unlock()
+	if !sent {
+		c.writeClose(code, reason)
+	}
+	return c.conn.Close()
+}
+
+// finish closes connection according to handler result and returns error
+// to be passed to `Wrapper`.
+func (c *WebSocketConn) finish(err error) error {
+	var (
+		ce *CloseError
+		he *HTTPError
+	)
+	switch {
+	case err == nil:
+		c.Close(CloseNormalClosure, "")
+		return nil
+	case errors.As(err, &ce):
+		c.Close(ce.Code, ce.Text)
+		if c.peerClosed || ce.Code == CloseNormalClosure || ce.Code == CloseGoingAway {
+			return nil
+		}
+	case errors.As(err, &he) && he.Code < http.StatusInternalServerError:
+		c.Close(ClosePolicyViolation, he.Message)
+	default:
+		c.Close(CloseInternalServerErr, "")
+	}
+	return err
+}
+
+// limit returns maximum size of incoming message.
+func (c *WebSocketConn) limit() int64 {
+	if c.readLimit <= 0 {
+		return maxWebSocketLimit
+	}
+	return c.readLimit
+}
+
+func (c *WebSocketConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	opcode = head[0] & 0x0f
+	masked := head[1]&0x80 != 0
+	n := uint64(head[1] & 0x7f)
+
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Text: "reserved bits set"}
+	}
+	if !masked {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Text: "client frame is not masked"}
+	}
+
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if opcode >= opClose && (!fin || n > maxControlPayload) {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Text: "invalid control frame"}
+	}
+	if n > uint64(c.limit()) {
+		return false, 0, nil, &CloseError{Code: CloseMessageTooBig, Text: "message too big"}
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return
+	}
+	// Payload grows as data arrives, so length announced by peer can't
+	// allocate memory up front.
+	var buf bytes.Buffer
+	if _, err = io.CopyN(&buf, c.br, int64(n)); err != nil {
+		return
+	}
+	payload = buf.Bytes()
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, opcode, payload, nil
+}
+
+func (c *WebSocketConn) handleClose(payload []byte) error {
+	c.peerClosed = true
+	ce := &CloseError{Code: CloseNoStatusReceived}
+	switch {
+	case len(payload) == 1:
+		return c.fail(CloseProtocolError, "invalid close frame")
+	case len(payload) >= 2:
+		ce.Code = int(binary.BigEndian.Uint16(payload))
+		ce.Text = string(payload[2:])
+		if !utf8.ValidString(ce.Text) {
+			return c.fail(CloseInvalidFramePayloadData, "invalid UTF-8")
+		}
+	}
+	code := ce.Code
+	switch {
+	case len(payload) == 0:
+		code = CloseNormalClosure
+	case !validCloseCode(code):
+		return c.fail(CloseProtocolError, "invalid close code")
+	}
+	c.writeClose(code, "")
+	return ce
+}
+
+// failRead turns read error into close error. Only EOF means connection is
+// closed by peer, other errors are local and returned as is.
+func (c *WebSocketConn) failRead(err error) error {
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		return c.fail(ce.Code, ce.Text)
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		c.peerClosed = true
+		return &CloseError{Code: CloseAbnormalClosure, Text: err.Error()}
+	}
+	return err
+}
+
+// fail sends close frame because of protocol violation.
+func (c *WebSocketConn) fail(code int, reason string) error {
+	c.writeClose(code, reason)
+	return &CloseError{Code: code, Text: reason}
+}
+
+func (c *WebSocketConn) writeClose(code int, reason string) error {
+	if !validCloseCode(code) {
+		return invalidCloseCode(code)
+	}
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+	payload := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], reason)
+	return c.writeFrame(opClose, payload)
+}
+
+// validCloseCode reports whether code can be sent in close frame.
+func validCloseCode(code int) bool {
+	switch {
+	case code >= CloseNormalClosure && code <= CloseUnsupportedData:
+		return true
+	case code >= CloseInvalidFramePayloadData && code <= 1014:
+		return true
+	}
+	return code >= 3000 && code <= 4999
+}
+
+func invalidCloseCode(code int) error {
+	return fmt.Errorf("mux: invalid close code %d", code)
+}
+
+func (c *WebSocketConn) writeFrame(opcode byte, payload []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closeSent {
+		return ErrWebSocketClosed
+	}
+	if opcode == opClose {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(payload)+10)
+	frame = append(frame, 0x80|opcode)
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, byte(n))
+	case n <= 0xffff:
+		frame = append(frame, 126, byte(n>>8), byte(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	frame = append(frame, payload...)
+	_, err := c.conn.Write(frame)
+	return err
+}
@@ -0,0 +1,346 @@
+package mux_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danikarik/mux"
+)
+
+const testWebSocketKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+type wsClient struct {
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+func dialWebSocket(t *testing.T, srv *httptest.Server, path string, header http.Header) (*wsClient, *http.Response) {
+	t.Helper()
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req, _ := http.NewRequest("GET", srv.URL+path, nil)
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", testWebSocketKey)
+	for k, vv := range header {
+		req.Header[k] = vv
+	}
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &wsClient{conn: conn, br: br}, resp
+}
+
+func (c *wsClient) write(t *testing.T, opcode byte, payload []byte) {
+	t.Helper()
+	mask := [4]byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask[:]...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	if _, err := c.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *wsClient) read(t *testing.T) (byte, []byte) {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	payload := make([]byte, head[1]&0x7f)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		t.Fatal(err)
+	}
+	return head[0] & 0x0f, payload
+}
+
+func (c *wsClient) readClose(t *testing.T) int {
+	t.Helper()
+	opcode, payload := c.read(t)
+	if opcode != 0x8 || len(payload) < 2 {
+		t.Fatalf("got opcode %d, expected close frame", opcode)
+	}
+	return int(binary.BigEndian.Uint16(payload))
+}
+
+func echoWebSocket(r *http.Request, conn *mux.WebSocketConn) error {
+	prefix := mux.Vars(r)["room"] + ":" + r.Context().Value(userID).(string) + ":"
+	for {
+		typ, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		if string(data) == "fail" {
+			return errors.New("storage unavailable")
+		}
+		if string(data) == "forbidden" {
+			return mux.NewHTTPError(http.StatusForbidden, "Forbidden")
+		}
+		if err := conn.WriteMessage(typ, append([]byte(prefix), data...)); err != nil {
+			return err
+		}
+	}
+}
+
+func newWebSocketServer(handled chan<- error) *httptest.Server {
+	errorFunc := func(err error, w http.ResponseWriter, r *http.Request) {
+		if handled != nil {
+			handled <- err
+		}
+		mux.NegotiateErrorFunc(err, w, r)
+	}
+	router := mux.NewRouter(mux.WithErrorHandler(errorFunc))
+	router.Use(func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		if r.URL.Query().Get("token") != "secret" {
+			return nil, mux.ErrUnauthorized
+		}
+		return context.WithValue(r.Context(), userID, "john"), nil
+	})
+	router.HandleWebSocket("/ws/{room}", echoWebSocket, mux.WebSocketSubprotocols("chat"))
+	return httptest.NewServer(router)
+}
+
+func TestWebSocketHandshake(t *testing.T) {
+	srv := newWebSocketServer(nil)
+	defer srv.Close()
+
+	testCases := []struct {
+		Name   string
+		Path   string
+		Header http.Header
+		Code   int
+	}{
+		{Name: "OK", Path: "/ws/lobby?token=secret", Code: http.StatusSwitchingProtocols},
+		{Name: "Unauthorized", Path: "/ws/lobby", Code: http.StatusUnauthorized},
+		{Name: "Version", Path: "/ws/lobby?token=secret", Header: http.Header{"Sec-Websocket-Version": {"8"}}, Code: http.StatusUpgradeRequired},
+		{Name: "Key", Path: "/ws/lobby?token=secret", Header: http.Header{"Sec-Websocket-Key": {"short"}}, Code: http.StatusBadRequest},
+		{Name: "Origin", Path: "/ws/lobby?token=secret", Header: http.Header{"Origin": {"http://evil.example.com"}}, Code: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			header := http.Header{"Sec-Websocket-Protocol": {"json, chat"}}
+			for k, vv := range tc.Header {
+				header[k] = vv
+			}
+			_, resp := dialWebSocket(t, srv, tc.Path, header)
+			if resp.StatusCode != tc.Code {
+				t.Fatal(newStatusError(resp.StatusCode, tc.Code))
+			}
+			if tc.Code != http.StatusSwitchingProtocols {
+				return
+			}
+			if got := resp.Header.Get("Sec-WebSocket-Accept"); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+				t.Fatalf("got accept key %q", got)
+			}
+			if got := resp.Header.Get("Sec-WebSocket-Protocol"); got != "chat" {
+				t.Fatalf("got subprotocol %q", got)
+			}
+		})
+	}
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	handled := make(chan error, 1)
+	srv := newWebSocketServer(handled)
+	defer srv.Close()
+
+	client, _ := dialWebSocket(t, srv, "/ws/lobby?token=secret", nil)
+
+	client.write(t, 0x1, []byte("hello"))
+	if opcode, data := client.read(t); opcode != 0x1 || string(data) != "lobby:john:hello" {
+		t.Fatalf("got opcode %d and %q", opcode, data)
+	}
+
+	client.write(t, 0x9, []byte("ping"))
+	if opcode, data := client.read(t); opcode != 0xa || string(data) != "ping" {
+		t.Fatalf("got opcode %d and %q, expected pong", opcode, data)
+	}
+
+	client.write(t, 0x8, []byte{0x03, 0xe8})
+	if code := client.readClose(t); code != mux.CloseNormalClosure {
+		t.Fatalf("got close code %d", code)
+	}
+
+	select {
+	case err := <-handled:
+		t.Fatalf("got %v, expected client close to be ignored", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestWebSocketCloseCodes(t *testing.T) {
+	testCases := []struct {
+		Name    string
+		Message string
+		Code    int
+	}{
+		{Name: "InternalError", Message: "fail", Code: mux.CloseInternalServerErr},
+		{Name: "HTTPError", Message: "forbidden", Code: mux.ClosePolicyViolation},
+		{Name: "InvalidUTF8", Message: "\xff\xfe", Code: mux.CloseInvalidFramePayloadData},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			handled := make(chan error, 1)
+			srv := newWebSocketServer(handled)
+			defer srv.Close()
+
+			client, _ := dialWebSocket(t, srv, "/ws/lobby?token=secret", nil)
+			client.write(t, 0x1, []byte(tc.Message))
+
+			if code := client.readClose(t); code != tc.Code {
+				t.Fatalf("got close code %d, expected %d", code, tc.Code)
+			}
+			select {
+			case err := <-handled:
+				if err == nil {
+					t.Fatal("expected error passed to wrapper")
+				}
+			case <-time.After(time.Second):
+				t.Fatal("expected error passed to wrapper")
+			}
+		})
+	}
+}
+
+func TestWebSocketBeforeUpgrade(t *testing.T) {
+	handled := make(chan error, 1)
+	errorFunc := func(err error, w http.ResponseWriter, r *http.Request) {
+		handled <- err
+		mux.NegotiateErrorFunc(err, w, r)
+	}
+	joinRoom := func(w http.ResponseWriter, r *http.Request) (context.Context, error) {
+		if mux.Vars(r)["room"] != "lobby" {
+			return nil, mux.NewHTTPError(http.StatusNotFound, "Room not found")
+		}
+		return context.WithValue(r.Context(), userID, "guest"), nil
+	}
+
+	router := mux.NewRouter(mux.WithErrorHandler(errorFunc))
+	router.HandleWebSocket("/ws/{room}", echoWebSocket, mux.WebSocketBeforeUpgrade(joinRoom))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	_, resp := dialWebSocket(t, srv, "/ws/secret", nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatal(newStatusError(resp.StatusCode, http.StatusNotFound))
+	}
+	select {
+	case err := <-handled:
+		var e *mux.HTTPError
+		if !errors.As(err, &e) || e.Code != http.StatusNotFound {
+			t.Fatalf("got %v, expected not found error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected error passed to wrapper")
+	}
+
+	client, resp := dialWebSocket(t, srv, "/ws/lobby", nil)
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatal(newStatusError(resp.StatusCode, http.StatusSwitchingProtocols))
+	}
+	client.write(t, 0x1, []byte("hello"))
+	if _, data := client.read(t); string(data) != "lobby:guest:hello" {
+		t.Fatalf("got %q, expected context from before upgrade", data)
+	}
+}
+
+func TestWebSocketHardReadLimit(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleWebSocket("/ws", func(r *http.Request, conn *mux.WebSocketConn) error {
+		_, _, err := conn.ReadMessage()
+		return err
+	}, mux.WebSocketReadLimit(0))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	client, _ := dialWebSocket(t, srv, "/ws", nil)
+	frame := []byte{0x82, 0x80 | 127}
+	frame = binary.BigEndian.AppendUint64(frame, 1<<40)
+	frame = append(frame, 1, 2, 3, 4)
+	if _, err := client.conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+	if code := client.readClose(t); code != mux.CloseMessageTooBig {
+		t.Fatalf("got close code %d, expected %d", code, mux.CloseMessageTooBig)
+	}
+}
+
+func TestWebSocketReadTimeout(t *testing.T) {
+	handled := make(chan error, 1)
+	errorFunc := func(err error, w http.ResponseWriter, r *http.Request) {
+		handled <- err
+	}
+	router := mux.NewRouter(mux.WithErrorHandler(errorFunc))
+	router.HandleWebSocket("/ws", func(r *http.Request, conn *mux.WebSocketConn) error {
+		conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+		_, _, err := conn.ReadMessage()
+		return err
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	client, _ := dialWebSocket(t, srv, "/ws", nil)
+	opcode, payload := client.read(t)
+	if opcode != 0x8 || len(payload) != 2 {
+		t.Fatalf("got opcode %d and %q, expected close frame without reason", opcode, payload)
+	}
+	if code := int(binary.BigEndian.Uint16(payload)); code != mux.CloseInternalServerErr {
+		t.Fatalf("got close code %d, expected %d", code, mux.CloseInternalServerErr)
+	}
+	select {
+	case err := <-handled:
+		var ne net.Error
+		if !errors.As(err, &ne) || !ne.Timeout() {
+			t.Fatalf("got %v, expected timeout error", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected timeout passed to wrapper")
+	}
+}
+
+func TestWebSocketInvalidCloseCode(t *testing.T) {
+	codes := make(chan error, 1)
+	router := mux.NewRouter()
+	router.HandleWebSocket("/ws", func(r *http.Request, conn *mux.WebSocketConn) error {
+		_, _, err := conn.ReadMessage()
+		codes <- conn.Close(mux.CloseAbnormalClosure, "")
+		return err
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	client, _ := dialWebSocket(t, srv, "/ws", nil)
+	client.write(t, 0x8, []byte{0x03, 0xed})
+	if code := client.readClose(t); code != mux.CloseProtocolError {
+		t.Fatalf("got close code %d, expected %d", code, mux.CloseProtocolError)
+	}
+	if err := <-codes; err == nil {
+		t.Fatal("expected close code 1006 to be refused")
+	}
+}